@@ -7,6 +7,7 @@ import (
 	"github.com/antorpo/os-go-concurrency/internal/application/usecase"
 	"github.com/antorpo/os-go-concurrency/internal/infrastructure/config"
 	"github.com/antorpo/os-go-concurrency/pkg/log"
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
@@ -48,6 +49,9 @@ func BuildApplication() (*Application, error) {
 		return nil, err
 	}
 
+	// Pipeline diagrams
+	app.registerPipelineRendering()
+
 	// Use Case
 	app.registerProductUseCase()
 
@@ -71,6 +75,14 @@ func (app *Application) registerConfiguration() error {
 	return nil
 }
 
+// registerPipelineRendering sets the pipeline package's diagram link settings once, before any request runs a pipeline.
+func (app *Application) registerPipelineRendering() {
+	pipeline.EncryptedMode = false
+	if renderServer := app.Config.GetConfig().App.RenderServer; renderServer != "" {
+		pipeline.RenderServer = renderServer
+	}
+}
+
 func (app *Application) registerProductUseCase() {
 	app.ProductUseCase = usecase.NewProductUseCase(app.Logger, app.Meter, app.Config)
 }
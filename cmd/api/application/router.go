@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/antorpo/os-go-concurrency/pkg/otel"
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
 	"github.com/gin-contrib/pprof"
 	"github.com/gin-gonic/gin"
 )
@@ -12,6 +13,9 @@ func RegisterRoutes(app *Application) {
 	// OTel middleware
 	app.Router.Use(otel.Middleware())
 
+	// Request ID propagation for pipeline traces
+	app.Router.Use(requestID())
+
 	// Pprof endpoints
 	pprof.Register(app.Router)
 
@@ -22,3 +26,15 @@ func RegisterRoutes(app *Application) {
 
 	app.Router.POST("/products", app.ProductController.ProcessProducts)
 }
+
+func requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(pipeline.RequestIDHeader)
+		if id != "" {
+			c.Request = c.Request.WithContext(pipeline.WithRequestID(c.Request.Context(), id))
+			c.Header(pipeline.RequestIDHeader, id)
+		}
+
+		c.Next()
+	}
+}
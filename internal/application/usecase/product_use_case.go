@@ -2,12 +2,14 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/antorpo/os-go-concurrency/internal/application/usecase/stage"
 	"github.com/antorpo/os-go-concurrency/internal/domain/entities"
 	"github.com/antorpo/os-go-concurrency/internal/infrastructure/config"
 	"github.com/antorpo/os-go-concurrency/pkg/log"
 	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
+	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -61,7 +63,13 @@ func (p *productUseCase) ProcessConcurrent(ctx context.Context, products *entiti
 
 	workersGauge.Record(ctx, int64(workers))
 
-	pipeline.EncryptedMode = false
+	externalRetry := &pipeline.RetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     50 * time.Millisecond,
+		MaxBackoff:  time.Second,
+		Jitter:      0.2,
+	}
+
 	productPipeline := &pipeline.Pipeline{
 		Name:   "Product pipeline",
 		Source: stage.Source,
@@ -75,20 +83,23 @@ func (p *productUseCase) ProcessConcurrent(ctx context.Context, products *entiti
 						Name: "External data-sources concurrent",
 						Streams: []pipeline.Flow{
 							{
-								&pipeline.SimplePipe{Resolver: stage.CheckAvailability},
+								&pipeline.SimplePipe{Resolver: stage.CheckAvailability, Retry: externalRetry},
 							},
 							{
-								&pipeline.SimplePipe{Resolver: stage.GetPricing},
+								&pipeline.SimplePipe{Resolver: stage.GetPricing, Retry: externalRetry},
 							},
 						},
 						Merger: stage.Merger,
 					},
 				},
-				Joiner: stage.Joiner,
-				Tagger: stage.ProductTagger,
+				Joiner:      stage.Joiner,
+				Tagger:      stage.ProductTagger,
+				ErrorPolicy: pipeline.CollectAll,
 			},
 		},
-		Sink: stage.Sink,
+		Sink:       stage.Sink,
+		SpanTracer: otel.Tracer("github.com/antorpo/os-go-concurrency/pipeline"),
+		Meter:      p.meter,
 	}
 
 	enrichedProducts, err := pipeline.Run(ctx, products, productPipeline, false)
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/antorpo/os-go-concurrency/internal/domain/entities"
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
 )
 
 type MergerHolder struct {
@@ -80,14 +81,26 @@ func Joiner(_ context.Context, input interface{}, results []interface{}) (interf
 		return nil, er
 	}
 
-	enrichedProducts := make([]entities.EnrichedProduct, len(results))
+	response := &entities.ResponseProducts{
+		Products: make([]entities.EnrichedProduct, 0, len(results)),
+	}
 
 	for i, product := range data.Products {
-		holder, _ := results[i].(*MergerHolder)
-		enrichedProducts[i] = CalculateEnrichment(holder.Availability, holder.Price, product)
+		result, _ := results[i].(pipeline.Result)
+		if result.Err != nil {
+			response.Errors = append(response.Errors, entities.ProductError{
+				ProductID: product.ProductID,
+				Error:     result.Err.Error(),
+			})
+
+			continue
+		}
+
+		holder, _ := result.Value.(*MergerHolder)
+		response.Products = append(response.Products, CalculateEnrichment(holder.Availability, holder.Price, product))
 	}
 
-	return &entities.ResponseProducts{Products: enrichedProducts}, nil
+	return response, nil
 }
 
 func Sink(_ context.Context, input interface{}) (interface{}, error) {
@@ -7,5 +7,6 @@ type Configuration struct {
 }
 
 type AppConfig struct {
-	Workers int `json:"workers"`
+	Workers      int    `json:"workers"`
+	RenderServer string `json:"render_server"`
 }
@@ -6,6 +6,7 @@ type RequestProducts struct {
 
 type ResponseProducts struct {
 	Products []EnrichedProduct `json:"products"`
+	Errors   []ProductError    `json:"errors,omitempty"`
 }
 
 type Product struct {
@@ -13,6 +14,11 @@ type Product struct {
 	Name      string `json:"name"`
 }
 
+type ProductError struct {
+	ProductID string `json:"product_id"`
+	Error     string `json:"error"`
+}
+
 type EnrichedProduct struct {
 	ProductID    string  `json:"product_id"`
 	Name         string  `json:"name"`
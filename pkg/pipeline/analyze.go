@@ -0,0 +1,298 @@
+package pipeline
+
+import (
+	"sort"
+	"time"
+)
+
+type (
+	// Analysis summarises where the time of a traced run went.
+	Analysis struct {
+		Wall         time.Duration  `json:"wall_ns"`
+		Busy         time.Duration  `json:"busy_ns"`
+		Parallelism  float64        `json:"parallelism"`
+		CriticalPath []CriticalStep `json:"critical_path"`
+		IteratorIdle []IteratorIdle `json:"iterator_idle,omitempty"`
+		FanOuts      []FanOut       `json:"fan_outs,omitempty"`
+	}
+
+	CriticalStep struct {
+		Kind     string        `json:"kind"`
+		Name     string        `json:"name"`
+		Branch   string        `json:"branch,omitempty"`
+		Duration time.Duration `json:"duration_ns"`
+	}
+
+	// IteratorIdle is the time a Batching Iterator spent waiting on chunk boundaries: Slack is the time finished
+	// branches sat idle until the slowest branch of their chunk was done, Gaps the time between chunks.
+	IteratorIdle struct {
+		Name   string        `json:"name"`
+		Branch string        `json:"branch,omitempty"`
+		Chunks int           `json:"chunks"`
+		Slack  time.Duration `json:"slack_ns"`
+		Gaps   time.Duration `json:"gaps_ns"`
+	}
+
+	FanOut struct {
+		Kind     string        `json:"kind"`
+		Name     string        `json:"name"`
+		Branch   string        `json:"branch,omitempty"`
+		Branches int           `json:"branches"`
+		Slowest  string        `json:"slowest"`
+		Duration time.Duration `json:"duration_ns"`
+	}
+
+	// interval is the time window a branch was running in.
+	interval struct {
+		start time.Time
+		end   time.Time
+	}
+)
+
+// Analyze computes the critical path, parallelism, Iterator idle time and slowest branch of every
+// fan-out of the trace, and marks the critical path so the traced diagram draws it highlighted.
+func (t *Trace) Analyze() *Analysis {
+	a := &Analysis{Wall: t.Duration}
+
+	a.walk(t.Stages)
+
+	if a.Wall > 0 {
+		a.Parallelism = float64(a.Busy) / float64(a.Wall)
+	}
+
+	for _, s := range critical(t.Stages) {
+		a.CriticalPath = append(a.CriticalPath, CriticalStep{Kind: s.Kind, Name: s.Name, Branch: s.Branch, Duration: s.Duration})
+
+		if s.node != nil {
+			s.node.mtx.Lock()
+			s.node.critical = true
+			s.node.mtx.Unlock()
+		}
+	}
+
+	return a
+}
+
+func (a *Analysis) walk(stages []TraceStage) {
+	for _, s := range stages {
+		if len(s.Branches) == 0 {
+			a.Busy += s.Duration
+			continue
+		}
+
+		switch s.Kind {
+		case "iterator", "broadcast", "partition", "race", "quorum":
+			a.FanOuts = append(a.FanOuts, fanOut(s))
+		}
+
+		if batching(s) {
+			a.IteratorIdle = append(a.IteratorIdle, iteratorIdle(s))
+		}
+
+		for _, b := range s.Branches {
+			a.walk(b.Stages)
+		}
+	}
+}
+
+// critical follows, for every stage on the way, the branch that finished last in each chunk of
+// branches: that is the one the stage had to wait for before moving on. Loop iterations run one after
+// the other, so all of them are on the path, and races and quorums only wait for their winners.
+func critical(stages []TraceStage) []TraceStage {
+	var path []TraceStage
+
+	for _, s := range stages {
+		path = append(path, s)
+
+		if len(s.Branches) == 0 {
+			continue
+		}
+
+		if s.Kind == "loop" {
+			for _, b := range s.Branches {
+				path = append(path, critical(b.Stages)...)
+			}
+
+			continue
+		}
+
+		if b, ok := decisive(s); ok {
+			path = append(path, critical(b.Stages)...)
+			continue
+		}
+
+		for _, chunk := range chunked(s.Branches) {
+			last := chunk[0]
+			for _, b := range chunk[1:] {
+				if intervalOf(b).end.After(intervalOf(last).end) {
+					last = b
+				}
+			}
+
+			path = append(path, critical(last.Stages)...)
+		}
+	}
+
+	return path
+}
+
+// decisive picks the successful branch that settled a race or quorum: the last to finish among the first
+// ones it required. Losers are canceled only after that, so they end later without holding the stage up.
+func decisive(s TraceStage) (TraceBranch, bool) {
+	var required int
+
+	switch {
+	case s.Kind == "race":
+		required = 1
+	case s.Kind == "quorum" && s.node != nil:
+		q, ok := s.node.pipe.(*Quorum)
+		if !ok {
+			return TraceBranch{}, false
+		}
+
+		required = q.required()
+	default:
+		return TraceBranch{}, false
+	}
+
+	var won []TraceBranch
+	for _, b := range s.Branches {
+		if succeeded(b) {
+			won = append(won, b)
+		}
+	}
+
+	if len(won) < required {
+		return TraceBranch{}, false
+	}
+
+	sort.SliceStable(won, func(i, j int) bool {
+		return intervalOf(won[i]).end.Before(intervalOf(won[j]).end)
+	})
+
+	return won[required-1], true
+}
+
+func succeeded(b TraceBranch) bool {
+	for _, s := range b.Stages {
+		if s.Start.IsZero() || s.End.IsZero() || s.Canceled || s.Error != "" {
+			return false
+		}
+	}
+
+	return len(b.Stages) > 0
+}
+
+func fanOut(s TraceStage) FanOut {
+	f := FanOut{Kind: s.Kind, Name: s.Name, Branch: s.Branch, Branches: len(s.Branches)}
+
+	for _, b := range s.Branches {
+		if d := intervalOf(b).duration(); d > f.Duration || f.Slowest == "" {
+			f.Slowest, f.Duration = b.Name, d
+		}
+	}
+
+	return f
+}
+
+// batching tells whether the stage is an Iterator running in chunks: pooled ones start the next item
+// as soon as a worker frees up, so they never sit idle on chunk boundaries.
+func batching(s TraceStage) bool {
+	if s.node == nil {
+		return false
+	}
+
+	i, ok := s.node.pipe.(*Iterator)
+
+	return ok && i.Scheduling == Batching
+}
+
+func iteratorIdle(s TraceStage) IteratorIdle {
+	var (
+		idle = IteratorIdle{Name: s.Name, Branch: s.Branch}
+		prev time.Time
+	)
+
+	for _, chunk := range chunked(s.Branches) {
+		end := chunkEnd(chunk)
+		for _, b := range chunk {
+			idle.Slack += end.Sub(intervalOf(b).end)
+		}
+
+		if !prev.IsZero() {
+			idle.Gaps += intervalOf(chunk[0]).start.Sub(prev)
+		}
+
+		idle.Chunks++
+		prev = end
+	}
+
+	return idle
+}
+
+// chunked rebuilds the chunks branches ran in from their timings: a chunk ends once every branch in
+// it is done, and whatever starts after that belongs to the next one. Branches that never ran are left out.
+func chunked(branches []TraceBranch) [][]TraceBranch {
+	started := make([]TraceBranch, 0, len(branches))
+	for _, b := range branches {
+		if !intervalOf(b).start.IsZero() {
+			started = append(started, b)
+		}
+	}
+
+	sort.SliceStable(started, func(i, j int) bool {
+		return intervalOf(started[i]).start.Before(intervalOf(started[j]).start)
+	})
+
+	var chunks [][]TraceBranch
+
+	for _, b := range started {
+		if n := len(chunks); n > 0 && intervalOf(b).start.Before(chunkEnd(chunks[n-1])) {
+			chunks[n-1] = append(chunks[n-1], b)
+			continue
+		}
+
+		chunks = append(chunks, []TraceBranch{b})
+	}
+
+	return chunks
+}
+
+func chunkEnd(chunk []TraceBranch) time.Time {
+	var end time.Time
+	for _, b := range chunk {
+		if iv := intervalOf(b); iv.end.After(end) {
+			end = iv.end
+		}
+	}
+
+	return end
+}
+
+func intervalOf(b TraceBranch) interval {
+	var iv interval
+
+	for _, s := range b.Stages {
+		if s.Start.IsZero() {
+			continue
+		}
+
+		if iv.start.IsZero() || s.Start.Before(iv.start) {
+			iv.start = s.Start
+		}
+
+		if s.End.After(iv.end) {
+			iv.end = s.End
+		}
+	}
+
+	return iv
+}
+
+func (iv interval) duration() time.Duration {
+	if iv.start.IsZero() || iv.end.IsZero() {
+		return 0
+	}
+
+	return iv.end.Sub(iv.start)
+}
@@ -0,0 +1,216 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const (
+	// FailFast cancels every sibling branch as soon as one of them fails.
+	FailFast ErrorPolicy = iota
+	// CollectAll runs every branch to completion and hands one Result per branch to the merger/joiner,
+	// with Seq set to the branch index.
+	CollectAll
+	// BestEffort runs every branch to completion and hands only the successful values to the merger/joiner.
+	BestEffort
+)
+
+type (
+	ErrorPolicy int
+
+	branch struct {
+		ctx  context.Context
+		flow Flow
+		data interface{}
+	}
+)
+
+func (p ErrorPolicy) String() string {
+	switch p {
+	case CollectAll:
+		return "collect-all"
+	case BestEffort:
+		return "best-effort"
+	default:
+		return "fail-fast"
+	}
+}
+
+func (p ErrorPolicy) run(ctx context.Context, branches []branch, b breaker, workers int) ([]interface{}, error) {
+	if p == FailFast && (workers <= 0 || workers >= len(branches)) {
+		return runFailFast(ctx, branches, b)
+	}
+
+	return p.runPooled(ctx, branches, b, workers)
+}
+
+func (p ErrorPolicy) collect(joined []interface{}) ([]interface{}, error) {
+	if p == FailFast {
+		return joined, nil
+	}
+
+	var (
+		out  []interface{}
+		errs []error
+	)
+
+	for _, j := range joined {
+		r := j.(Result)
+
+		switch {
+		case p == CollectAll:
+			out = append(out, r)
+		case r.Err == nil:
+			out = append(out, r.Value)
+		}
+
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+		}
+	}
+
+	if p == BestEffort && len(joined) > 0 && len(errs) == len(joined) {
+		return nil, errors.Join(errs...)
+	}
+
+	return out, nil
+}
+
+func (p ErrorPolicy) incomplete(expected, actual int) bool {
+	return p == FailFast && isCancelled(expected, actual)
+}
+
+func runFailFast(ctx context.Context, branches []branch, b breaker) ([]interface{}, error) {
+	var (
+		pathOuts = make([]<-chan interface{}, len(branches))
+		pathErrs []<-chan error
+	)
+
+	for idx, br := range branches {
+		bCtx, end := branchSpan(br.ctx)
+
+		pathIn := make(chan interface{}, 1)
+		pathOut, ferr := connectFlow(bCtx, pathIn, br.flow, b)
+
+		pathOuts[idx] = pathOut
+		pathErrs = append(pathErrs, endWith(ferr, end)...)
+
+		go feed(bCtx, pathIn, br.data)
+	}
+
+	return mergeAll(
+		func() <-chan interface{} { return fanIn(ctx, pathOuts...) },
+		pathErrs,
+	)
+}
+
+func (p ErrorPolicy) runPooled(ctx context.Context, branches []branch, b breaker, workers int) ([]interface{}, error) {
+	if workers <= 0 || workers > len(branches) {
+		workers = len(branches)
+	}
+
+	var (
+		results   = make([]Result, len(branches))
+		completed = make([]bool, len(branches))
+		queue     = make(chan int)
+		wg        sync.WaitGroup
+	)
+
+	wg.Add(workers)
+
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+
+			for idx := range queue {
+				results[idx], completed[idx] = p.runBranch(branches[idx], b)
+				results[idx].Seq = idx
+			}
+		}()
+	}
+
+enqueue:
+	for idx := range branches {
+		select {
+		case queue <- idx:
+		case <-ctx.Done():
+			break enqueue
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	return p.gather(ctx, results, completed)
+}
+
+func (p ErrorPolicy) runBranch(br branch, b breaker) (Result, bool) {
+	bCtx, bb := br.ctx, b
+
+	if p != FailFast {
+		bCtx, bb = newBreaker(br.ctx)
+		defer bb.cancel()
+	}
+
+	return runOn(bCtx, br, bb)
+}
+
+func runOn(ctx context.Context, br branch, b breaker) (r Result, ok bool) {
+	ctx, end := branchSpan(ctx)
+	if end != nil {
+		defer func() { end(r.Err) }()
+	}
+
+	pathIn := make(chan interface{}, 1)
+	pathOut, errs := connectFlow(ctx, pathIn, br.flow, b)
+
+	go feed(ctx, pathIn, br.data)
+
+	if err := WaitForPipeline(errs...); err != nil {
+		return Result{Err: err}, true
+	}
+
+	if v, ok := <-pathOut; ok {
+		return Result{Value: v}, true
+	}
+
+	return Result{}, false
+}
+
+func (p ErrorPolicy) gather(ctx context.Context, results []Result, completed []bool) ([]interface{}, error) {
+	out := make([]interface{}, 0, len(results))
+
+	for idx, r := range results {
+		if p == FailFast {
+			if r.Err != nil {
+				return nil, r.Err
+			}
+
+			if completed[idx] {
+				out = append(out, r.Value)
+			}
+
+			continue
+		}
+
+		if !completed[idx] {
+			r.Err = ctx.Err()
+			if r.Err == nil {
+				r.Err = context.Canceled
+			}
+		}
+
+		out = append(out, r)
+	}
+
+	return out, nil
+}
+
+func policyNote(p ErrorPolicy) string {
+	if p == FailFast {
+		return ""
+	}
+
+	return "//" + p.String() + "//"
+}
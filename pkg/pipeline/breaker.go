@@ -8,11 +8,45 @@ import (
 type (
 	breaker struct {
 		context.CancelFunc
-		done chan interface{}
-		once *sync.Once
+		done   chan interface{}
+		once   *sync.Once
+		stream bool
+		item   bool
+	}
+
+	// settled is a stream item that exited early or failed: it skips the remaining stages on its way to
+	// the sink, keeping its place in the stream.
+	settled struct {
+		value interface{}
+		err   error
 	}
 )
 
+const exitKey ctxKey = "pipeline.exit"
+
+func Exit(ctx context.Context, value interface{}) {
+	if _, ok := ctx.Value(exitKey).(*breaker); !ok {
+		return
+	}
+
+	traceExit(ctx)
+	exit(ctx, value)
+}
+
+func exit(ctx context.Context, value interface{}) {
+	b, ok := ctx.Value(exitKey).(*breaker)
+	if !ok {
+		return
+	}
+
+	b.earlyExit(value)
+	b.cancel()
+}
+
+func withExit(ctx context.Context, b breaker) context.Context {
+	return context.WithValue(ctx, exitKey, &b)
+}
+
 func newBreaker(ctx context.Context) (context.Context, breaker) {
 	cCtx, cancelFunc := context.WithCancel(ctx)
 	return cCtx, breaker{
@@ -32,3 +66,48 @@ func (b *breaker) earlyExit(value interface{}) {
 		close(b.done)
 	})
 }
+
+func (b *breaker) exited() (interface{}, bool) {
+	select {
+	case value, ok := <-b.done:
+		return value, ok
+	default:
+		return nil, false
+	}
+}
+
+// scope hands nested flows the breaker of the run, or of the stream item, they belong to. Only the
+// top-level stage settles a stream item.
+func (b breaker) scope() breaker {
+	b.item = false
+
+	return b
+}
+
+// scopeItem gives a single stream item its own exit slot, so an Exit or error while handling it ends that item only.
+func (b *breaker) scopeItem(ctx context.Context) (context.Context, breaker) {
+	iCtx, ib := newBreaker(ctx)
+	ib.item = true
+
+	return withExit(iCtx, ib), ib
+}
+
+// settle releases the item and, when it exited early or failed, sends that downstream in place of the
+// stage output. Whichever came first wins.
+func (b *breaker) settle(ctx context.Context, out chan interface{}) bool {
+	b.cancel()
+
+	value, exited := b.exited()
+	if !exited {
+		return false
+	}
+
+	s, ok := value.(settled)
+	if !ok {
+		s = settled{value: value}
+	}
+
+	emit(ctx, out, s)
+
+	return true
+}
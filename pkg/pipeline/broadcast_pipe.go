@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 type (
@@ -13,6 +14,10 @@ type (
 
 		Streams []Flow
 		Merger  FanInFn
+		Timeout time.Duration
+
+		ErrorPolicy ErrorPolicy
+		Middlewares []Middleware
 	}
 )
 
@@ -30,15 +35,9 @@ func (b *Broadcast) connect(
 
 	panicProof(
 		func() {
-			select {
-			case data, ok := <-in:
-				if ok {
-					b.handleInput(ctx, out, errors, tracer, br, data)
-				}
-			case <-ctx.Done():
-				tracer.canceled()
-				return
-			}
+			consume(ctx, in, out, tracer, br, func(ctx context.Context, br breaker, data interface{}) {
+				b.handleInput(ctx, out, errors, tracer, br, data)
+			})
 		},
 		notifyPanicAsError(ctx, errors, br, tracer),
 		closeOutput(out, errors),
@@ -57,26 +56,41 @@ func (b *Broadcast) handleInput(
 ) {
 	defer tracer.done()
 
-	tracer.start(ctx)
+	ctx = tracer.start(ctx)
+
+	timer := newStageTimer(ctx, b, b.Name, b.Timeout, tracer)
+	defer timer.stop()
 
-	joined, err := b.runFlows(ctx, data, br)
+	sb := br.scope()
+
+	joined, err := bounded(timer, func() ([]interface{}, error) {
+		return b.runFlows(timer, data, sb)
+	})
 	if err != nil {
-		cancel(tracer, err, errors, br)
+		timer.abort(tracer, err, errors, br)
 		return
 	}
 
-	if isCancelled(len(b.Streams), len(joined)) {
+	if b.ErrorPolicy.incomplete(len(b.Streams), len(joined)) {
 		tracer.canceled()
 		return
 	}
 
-	merged, err := b.Merger(ctx, joined)
+	joined, err = b.ErrorPolicy.collect(joined)
+	if err != nil {
+		fail(tracer, err, errors, br)
+		return
+	}
+
+	merged, err := bounded(timer, func() (interface{}, error) {
+		return invoke(timer, KindMerger, b.Name, b.Merger, b.Middlewares, joined, mergeWith(b.Merger))
+	})
 	if err != nil {
 		fail(tracer, err, errors, br)
 		return
 	}
 
-	out <- merged
+	emit(ctx, out, merged)
 }
 
 func (b *Broadcast) runFlows(
@@ -88,28 +102,20 @@ func (b *Broadcast) runFlows(
 	error,
 ) {
 	var (
-		pathOuts = make([]<-chan interface{}, len(b.Streams))
-		pathErrs []<-chan error
+		branches = make([]branch, len(b.Streams))
 		flowCtx  context.Context
 	)
 
 	for idx, flow := range b.Streams {
-		pathIn := make(chan interface{}, 1)
-
 		branchName := fmt.Sprintf("%s#%v", b.Name, idx)
 		flowCtx = openBranch(CtxBranch(ctx, branchName), b, branchName)
-		pathOut, ferr := connectFlow(flowCtx, pathIn, flow, br)
-
-		pathOuts[idx] = pathOut
-		pathErrs = append(pathErrs, ferr...)
 
-		go feed(flowCtx, pathIn, data)
+		branches[idx] = branch{ctx: flowCtx, flow: flow, data: data}
 	}
 
-	return mergeAll(
-		func() <-chan interface{} { return fanIn(ctx, pathOuts...) },
-		pathErrs,
-	)
+	recordFanOut(ctx, b, len(branches))
+
+	return b.ErrorPolicy.run(ctx, branches, br, 0)
 }
 
 func (b *Broadcast) draw(s Skin) string {
@@ -143,6 +149,11 @@ func (b *Broadcast) comments() string {
 	output += "note right \n"
 	output += fontMultiline(b.Label, "<font size=\"24\">")
 
+	if policy := policyNote(b.ErrorPolicy); policy != "" {
+		output += "\n"
+		output += policy
+	}
+
 	if b.Comments != "" {
 		output += "\n"
 		output += b.Comments
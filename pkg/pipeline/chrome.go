@@ -0,0 +1,202 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	chromePid      = 1
+	chromeMainLane = "main"
+)
+
+type (
+	chromeEvent struct {
+		Name  string                 `json:"name"`
+		Cat   string                 `json:"cat,omitempty"`
+		Ph    string                 `json:"ph"`
+		Ts    float64                `json:"ts"`
+		Dur   float64                `json:"dur,omitempty"`
+		Pid   int                    `json:"pid"`
+		Tid   int                    `json:"tid"`
+		Scope string                 `json:"s,omitempty"`
+		Args  map[string]interface{} `json:"args,omitempty"`
+	}
+
+	chromeTrace struct {
+		TraceEvents     []chromeEvent     `json:"traceEvents"`
+		DisplayTimeUnit string            `json:"displayTimeUnit"`
+		OtherData       map[string]string `json:"otherData,omitempty"`
+	}
+
+	// chromeLanes gives every branch path its own thread id, in order of first appearance.
+	chromeLanes struct {
+		start  time.Time
+		ids    map[string]int
+		events []chromeEvent
+	}
+)
+
+// ChromeJSON exports the trace in the Chrome trace-event format, ready for chrome://tracing or Perfetto.
+// Each branch runs in its own lane, stages are duration events and failures, cancellations and notes are instant events.
+func (t *Trace) ChromeJSON() ([]byte, error) {
+	l := &chromeLanes{start: t.Start, ids: make(map[string]int)}
+
+	l.events = append(l.events, chromeEvent{
+		Name: "process_name",
+		Ph:   "M",
+		Pid:  chromePid,
+		Args: map[string]interface{}{"name": t.Pipeline},
+	})
+
+	main := l.lane("")
+
+	l.events = append(l.events, chromeEvent{
+		Name: t.Pipeline,
+		Cat:  "pipeline",
+		Ph:   "X",
+		Ts:   l.ts(t.Start),
+		Dur:  micros(t.Duration),
+		Pid:  chromePid,
+		Tid:  main,
+		Args: map[string]interface{}{"txn": t.TxnID},
+	})
+
+	for _, note := range t.SourceNotes {
+		l.instant(main, "note", "source", t.Start, map[string]interface{}{"note": note})
+	}
+
+	if t.SourceExited {
+		l.instant(main, "early exit", "source", t.Start, nil)
+	}
+
+	l.stages(t.Stages)
+
+	for _, note := range t.SinkNotes {
+		l.instant(main, "note", "sink", t.End, map[string]interface{}{"note": note})
+	}
+
+	return json.Marshal(chromeTrace{
+		TraceEvents:     l.events,
+		DisplayTimeUnit: "ms",
+		OtherData:       map[string]string{"txn": t.TxnID, "pipeline": t.Pipeline},
+	})
+}
+
+func (l *chromeLanes) lane(path string) int {
+	if id, ok := l.ids[path]; ok {
+		return id
+	}
+
+	id := len(l.ids) + 1
+	l.ids[path] = id
+
+	name := path
+	if name == "" {
+		name = chromeMainLane
+	}
+
+	l.events = append(l.events,
+		chromeEvent{Name: "thread_name", Ph: "M", Pid: chromePid, Tid: id, Args: map[string]interface{}{"name": name}},
+		chromeEvent{Name: "thread_sort_index", Ph: "M", Pid: chromePid, Tid: id, Args: map[string]interface{}{"sort_index": id}},
+	)
+
+	return id
+}
+
+func (l *chromeLanes) stages(stages []TraceStage) {
+	for _, s := range stages {
+		if s.Start.IsZero() {
+			continue
+		}
+
+		tid := l.lane(s.Branch)
+		args := map[string]interface{}{"kind": s.Kind}
+
+		if s.Branch != "" {
+			args["branch"] = s.Branch
+		}
+
+		if s.Timeout > 0 {
+			args["timeout"] = s.Timeout.String()
+		}
+
+		if s.Error != "" {
+			args["error"] = s.Error
+		}
+
+		l.events = append(l.events, chromeEvent{
+			Name: s.Name,
+			Cat:  s.Kind,
+			Ph:   "X",
+			Ts:   l.ts(s.Start),
+			Dur:  micros(s.Duration),
+			Pid:  chromePid,
+			Tid:  tid,
+			Args: args,
+		})
+
+		for _, a := range s.Attempts {
+			var aArgs map[string]interface{}
+			if a.Error != "" {
+				aArgs = map[string]interface{}{"error": a.Error}
+			}
+
+			l.events = append(l.events, chromeEvent{
+				Name: fmt.Sprintf("attempt #%d", a.Number),
+				Cat:  "attempt",
+				Ph:   "X",
+				Ts:   l.ts(a.Start),
+				Dur:  micros(a.Duration),
+				Pid:  chromePid,
+				Tid:  tid,
+				Args: aArgs,
+			})
+		}
+
+		switch {
+		case s.Error != "":
+			l.instant(tid, "☠ failed", s.Kind, s.End, map[string]interface{}{"stage": s.Name, "error": s.Error})
+		case s.Canceled:
+			l.instant(tid, "canceled", s.Kind, s.End, map[string]interface{}{"stage": s.Name})
+		}
+
+		if s.Exited {
+			l.instant(tid, "early exit", s.Kind, s.End, map[string]interface{}{"stage": s.Name})
+		}
+
+		for _, note := range s.Notes {
+			l.instant(tid, "note", s.Kind, s.End, map[string]interface{}{"stage": s.Name, "note": note})
+		}
+
+		for _, b := range s.Branches {
+			l.stages(b.Stages)
+		}
+	}
+}
+
+func (l *chromeLanes) instant(tid int, name, cat string, at time.Time, args map[string]interface{}) {
+	l.events = append(l.events, chromeEvent{
+		Name:  name,
+		Cat:   cat,
+		Ph:    "i",
+		Ts:    l.ts(at),
+		Pid:   chromePid,
+		Tid:   tid,
+		Scope: "t",
+		Args:  args,
+	})
+}
+
+func (l *chromeLanes) ts(at time.Time) float64 {
+	if at.IsZero() {
+		return 0
+	}
+
+	return micros(at.Sub(l.start))
+}
+
+func micros(d time.Duration) float64 {
+	return float64(d) / float64(time.Microsecond)
+}
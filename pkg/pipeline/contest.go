@@ -0,0 +1,92 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+func contest(
+	ctx context.Context,
+	branches []branch,
+	required int,
+	hedge time.Duration,
+) (
+	[]interface{},
+	error,
+) {
+	if required <= 0 || required > len(branches) {
+		return nil, fmt.Errorf("%d successful branches required out of %d", required, len(branches))
+	}
+
+	var (
+		results  = make(chan Result, len(branches))
+		breakers = make([]breaker, 0, len(branches))
+		winners  []interface{}
+		errs     []error
+		running  int
+	)
+
+	defer func() {
+		for _, b := range breakers {
+			b.cancel()
+		}
+	}()
+
+	start := func() {
+		br := branches[len(breakers)]
+		bCtx, bb := newBreaker(br.ctx)
+		breakers = append(breakers, bb)
+		running++
+
+		go func() {
+			r, ok := runOn(bCtx, br, bb)
+			if !ok && r.Err == nil {
+				r.Err = context.Canceled
+			}
+
+			results <- r
+		}()
+	}
+
+	for len(winners) < required {
+		if len(errs) > len(branches)-required {
+			return nil, fmt.Errorf(
+				"%d of %d branches failed, %d successful required: %w",
+				len(errs), len(branches), required, errors.Join(errs...),
+			)
+		}
+
+		var next <-chan time.Time
+
+		if len(breakers) < len(branches) {
+			if running == 0 || hedge <= 0 {
+				start()
+				continue
+			}
+
+			next = time.After(hedge)
+		}
+
+		select {
+		case r := <-results:
+			running--
+
+			if r.Err != nil {
+				errs = append(errs, r.Err)
+				continue
+			}
+
+			winners = append(winners, r.Value)
+
+		case <-next:
+			start()
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return winners, nil
+}
@@ -0,0 +1,310 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	dotStage    = "box"
+	dotMap      = "parallelogram"
+	dotFilter   = "diamond"
+	dotTap      = "cds"
+	dotDecision = "diamond"
+	dotFork     = "hexagon"
+	dotMerge    = "invtrapezium"
+	dotJunction = "circle"
+	dotTerminal = "oval"
+	dotLinked   = "component"
+)
+
+// latencyPalette goes from the fastest to the slowest observed stage.
+var latencyPalette = []string{"#c8e6c9", "#fff59d", "#ffcc80", "#ef9a9a"}
+
+type (
+	dotGraph struct {
+		nodes    strings.Builder
+		edges    []string
+		ids      int
+		depth    int
+		latency  *latency
+		slowest  time.Duration
+		clusters int
+	}
+)
+
+func (p *Pipeline) DOT() string {
+	g := &dotGraph{depth: 1, slowest: slowest(p)}
+
+	g.pipeline(nil, p)
+
+	out := fmt.Sprintf("digraph %s {\n", dotQuote(p.Name))
+	out += "  rankdir=TB;\n"
+	out += "  labelloc=t;\n"
+	out += fmt.Sprintf("  label=%s;\n", dotQuote(p.Name))
+	out += "  node [shape=box, style=\"rounded,filled\", fillcolor=white, fontname=\"Helvetica\"];\n"
+	out += "  edge [fontname=\"Helvetica\", fontsize=10];\n"
+	out += g.nodes.String()
+
+	for _, e := range g.edges {
+		out += "  " + e + "\n"
+	}
+
+	out += "}\n"
+
+	return out
+}
+
+func (g *dotGraph) line(s string) {
+	g.nodes.WriteString(strings.Repeat("  ", g.depth))
+	g.nodes.WriteString(s)
+	g.nodes.WriteString("\n")
+}
+
+func (g *dotGraph) node(shape, label string, pipe Traceable) string {
+	g.ids++
+
+	var (
+		id    = fmt.Sprintf("n%d", g.ids)
+		attrs = fmt.Sprintf("shape=%s", shape)
+	)
+
+	if pipe != nil && g.latency != nil {
+		if o, ok := g.latency.of(pipe); ok {
+			label += fmt.Sprintf("\navg %v · max %v", o.mean().Round(time.Microsecond), o.max.Round(time.Microsecond))
+			attrs += fmt.Sprintf(", fillcolor=%s", dotQuote(g.colour(o.mean())))
+		}
+	}
+
+	g.line(fmt.Sprintf("%s [label=%s, %s];", id, dotQuote(label), attrs))
+
+	return id
+}
+
+func (g *dotGraph) link(from []openEdge, to string) {
+	for _, f := range from {
+		if f.label == "" {
+			g.edges = append(g.edges, fmt.Sprintf("%s -> %s;", f.id, to))
+			continue
+		}
+
+		g.edges = append(g.edges, fmt.Sprintf("%s -> %s [label=%s];", f.id, to, dotQuote(f.label)))
+	}
+}
+
+func (g *dotGraph) step(from []openEdge, shape, label string, pipe Traceable) []openEdge {
+	id := g.node(shape, label, pipe)
+	g.link(from, id)
+
+	return []openEdge{{id: id}}
+}
+
+func (g *dotGraph) cluster(label string, draw func()) {
+	g.clusters++
+
+	g.line(fmt.Sprintf("subgraph cluster_%d {", g.clusters))
+	g.depth++
+	g.line(fmt.Sprintf("label=%s;", dotQuote(label)))
+	g.line("style=\"rounded,dashed\";")
+	g.line("color=\"#9e9e9e\";")
+	draw()
+	g.depth--
+	g.line("}")
+}
+
+func (g *dotGraph) pipeline(from []openEdge, p *Pipeline) []openEdge {
+	parent := g.latency
+	g.latency = &p.latency
+
+	defer func() { g.latency = parent }()
+
+	ends := g.step(from, dotTerminal, mermaidName(named(p.SourceName, p.Source), "source"), nil)
+	ends = g.flow(ends, p.Flow)
+
+	return g.step(ends, dotTerminal, mermaidName(named(p.SinkName, p.Sink), "sink"), nil)
+}
+
+func (g *dotGraph) flow(from []openEdge, flow Flow) []openEdge {
+	for _, pipe := range flow {
+		from = g.pipe(from, pipe)
+	}
+
+	return from
+}
+
+// branches draws each flow out of the fork node, boxing every non-empty one in its own cluster when clustered is set.
+func (g *dotGraph) branches(fork string, labels []string, flows []Flow, clustered bool) []openEdge {
+	var ends []openEdge
+
+	for i, flow := range flows {
+		from := []openEdge{{id: fork, label: labels[i]}}
+
+		if !clustered || len(flow) == 0 {
+			ends = append(ends, g.flow(from, flow)...)
+			continue
+		}
+
+		name := labels[i]
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+		}
+
+		g.cluster(name, func() {
+			ends = append(ends, g.flow(from, flow)...)
+		})
+	}
+
+	return ends
+}
+
+func (g *dotGraph) pipe(from []openEdge, pipe Pipe) []openEdge {
+	stage, _ := describe(pipe)
+
+	switch p := pipe.(type) {
+	case *SimplePipe:
+		return g.step(from, dotStage, stage, p)
+	case *Map:
+		return g.step(from, dotMap, stage, p)
+	case *Filter:
+		return g.step(from, dotFilter, stage, p)
+	case *Tap:
+		return g.step(from, dotTap, stage, p)
+
+	case *Broadcast:
+		fork := g.step(from, dotFork, p.Name, p)[0].id
+		ends := g.branches(fork, make([]string, len(p.Streams)), p.Streams, true)
+
+		return g.step(ends, dotMerge, mermaidName(p.Merger, "merge"), nil)
+
+	case *Race:
+		fork := g.step(from, dotFork, p.Name, p)[0].id
+		ends := g.branches(fork, make([]string, len(p.Streams)), p.Streams, true)
+
+		return g.step(ends, dotJunction, "first", nil)
+
+	case *Quorum:
+		fork := g.step(from, dotFork, p.Name, p)[0].id
+		ends := g.branches(fork, make([]string, len(p.Streams)), p.Streams, true)
+
+		return g.step(ends, dotMerge, fmt.Sprintf("%s\n%d of %d", mermaidName(p.Merger, "merge"), p.required(), len(p.Streams)), nil)
+
+	case *Iterator:
+		fork := g.step(from, dotFork, p.Name, p)[0].id
+		ends := g.branches(fork, []string{"each"}, []Flow{p.Stream}, true)
+
+		return g.step(ends, dotMerge, mermaidName(p.Joiner, "join"), nil)
+
+	case *Loop:
+		entry := g.step(from, dotJunction, "↻", p)
+		ends := g.flow(entry, p.Stream)
+
+		if p.While == nil {
+			return ends
+		}
+
+		decision := g.step(ends, dotDecision, p.Name+"?", nil)[0].id
+		g.link([]openEdge{{id: decision, label: "yes"}}, entry[0].id)
+
+		return []openEdge{{id: decision, label: "no"}}
+
+	case *IfPipe:
+		volume := p.traffic.flowVolume(p.TrafficWindow)
+		decision := g.step(from, dotDecision, p.Name+"?", p)[0].id
+
+		return g.branches(
+			decision,
+			[]string{dotVolume("yes", volume["true"]), dotVolume("no", volume["false"])},
+			[]Flow{p.TrueFlow, p.FalseFlow},
+			false,
+		)
+
+	case *Switch:
+		volume := p.traffic.flowVolume(p.TrafficWindow)
+		decision := g.step(from, dotDecision, p.Name+"?", p)[0].id
+
+		var labels []string
+		for _, k := range sortedKeys(p.Cases) {
+			labels = append(labels, dotVolume(k, volume[k]))
+		}
+
+		labels = append(labels, dotVolume(caseName(defaultCase), volume[defaultCase]))
+
+		return g.branches(decision, labels, append(sortedFlows(p.Cases), p.Default), false)
+
+	case *PartitionPipe:
+		volume := p.traffic.flowVolume(p.TrafficWindow)
+		fork := g.step(from, dotFork, p.Name, p)[0].id
+
+		var labels []string
+		for _, k := range sortedKeys(p.Paths) {
+			labels = append(labels, dotVolume(k, volume[k]))
+		}
+
+		ends := g.branches(fork, labels, sortedFlows(p.Paths), true)
+
+		return g.step(ends, dotMerge, mermaidName(p.Merger, "merge"), nil)
+
+	case *SubPipeline:
+		sp := p.pipeline()
+
+		if p.Linked {
+			return g.step(from, dotLinked, sp.Name, p)
+		}
+
+		var ends []openEdge
+
+		g.cluster(sp.Name, func() {
+			ends = g.pipeline(from, sp)
+		})
+
+		return ends
+
+	default:
+		return g.step(from, dotStage, stage, nil)
+	}
+}
+
+func (g *dotGraph) colour(mean time.Duration) string {
+	if g.slowest <= 0 {
+		return latencyPalette[0]
+	}
+
+	idx := int(float64(mean) / float64(g.slowest) * float64(len(latencyPalette)))
+	if idx >= len(latencyPalette) {
+		idx = len(latencyPalette) - 1
+	}
+
+	return latencyPalette[idx]
+}
+
+// slowest is the highest mean latency observed across the pipeline and its nested sub-pipelines.
+func slowest(p *Pipeline) time.Duration {
+	var max time.Duration
+
+	walk(p.Flow, func(pipe Pipe) {
+		if sp, ok := pipe.(*SubPipeline); ok {
+			if m := slowest(sp.pipeline()); m > max {
+				max = m
+			}
+		}
+
+		if o, ok := p.latency.of(pipe); ok && o.mean() > max {
+			max = o.mean()
+		}
+	})
+
+	return max
+}
+
+func dotVolume(label string, volume flowsPercent) string {
+	if volume.total <= 0 {
+		return label
+	}
+
+	return fmt.Sprintf("%s\n%.2f%%", label, volume.total)
+}
+
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(s) + "\""
+}
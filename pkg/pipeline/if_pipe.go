@@ -3,7 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
-	"sync"
+	"time"
 )
 
 type (
@@ -15,9 +15,11 @@ type (
 		TrueFlow      Flow
 		FalseFlow     Flow
 		TrafficTagger TrafficTagger
+		TrafficWindow time.Duration
+		Timeout       time.Duration
+		Middlewares   []Middleware
 
-		mtx      sync.Mutex
-		counters map[string]*flowCounter
+		traffic traffic
 	}
 )
 
@@ -35,15 +37,9 @@ func (s *IfPipe) connect(
 
 	panicProof(
 		func() {
-			select {
-			case data, ok := <-in:
-				if ok {
-					s.handleInput(ctx, out, errors, tracer, b, data)
-				}
-			case <-ctx.Done():
-				tracer.canceled()
-				return
-			}
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				s.handleInput(ctx, out, errors, tracer, b, data)
+			})
 		},
 		notifyPanicAsError(ctx, errors, b, tracer),
 		closeOutput(out, errors),
@@ -61,28 +57,38 @@ func (s *IfPipe) handleInput(
 	data interface{},
 ) {
 	defer tracer.done()
-	tracer.start(ctx)
+	ctx = tracer.start(ctx)
 
 	pipeIn := make(chan interface{}, 1)
 
-	isTrue, err := s.Decider(ctx, data)
+	timer := newStageTimer(ctx, s, s.Name, s.Timeout, tracer)
+	defer timer.stop()
+
+	isTrue, err := bounded(timer, func() (bool, error) {
+		return invoke(timer, KindDecider, s.Name, s.Decider, s.Middlewares, data, s.Decider)
+	})
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
 	}
 
-	flowCtx := openBranch(CtxBranch(ctx, fmt.Sprintf("%s#%v", s.Name, isTrue)), s, fmt.Sprint(isTrue))
+	sb := b.scope()
+
+	flowCtx := openBranch(CtxBranch(timer, fmt.Sprintf("%s#%v", s.Name, isTrue)), s, fmt.Sprint(isTrue))
 	flow := s.selectedFlow(ctx, data, isTrue)
-	pOut, pErrs := connectFlow(flowCtx, pipeIn, flow, b)
+	pOut, pErrs := connectFlow(flowCtx, pipeIn, flow, sb)
 
 	go feed(flowCtx, pipeIn, data)
 
-	for e := range mergeErrors(pErrs...) {
-		tracer.canceled()
-		errors <- e
+	_, err = bounded(timer, func() (interface{}, error) {
+		return nil, WaitForPipeline(pErrs...)
+	})
+	if err != nil {
+		timer.abort(tracer, err, errors, b)
+		return
 	}
 
-	done(ctx, out, pOut, tracer)
+	done(timer, out, pOut, tracer)
 }
 
 func (s *IfPipe) selectedFlow(ctx context.Context, data interface{}, isTrue bool) Flow {
@@ -93,15 +99,27 @@ func (s *IfPipe) selectedFlow(ctx context.Context, data interface{}, isTrue bool
 		flow = s.FalseFlow
 	}
 
-	s.count(ctx, data, fmt.Sprint(isTrue))
+	s.traffic.count(ctx, fmt.Sprint(isTrue), s.TrafficTagger, data, s.TrafficWindow)
 
 	return flow
 }
 
+func (s *IfPipe) Stats() TrafficStats {
+	return s.traffic.stats(s.TrafficWindow)
+}
+
+func (s *IfPipe) ResetStats() {
+	s.traffic.reset()
+}
+
+func (s *IfPipe) flows() *traffic {
+	return &s.traffic
+}
+
 func (s *IfPipe) draw(skin Skin) string {
 	var output string
 
-	volume := s.flowVolume()
+	volume := s.traffic.flowVolume(s.TrafficWindow)
 	output += fmt.Sprintf("if (%s?) then (yes)\n", s.Name)
 	output += drawFlowVolume("left", volume["true"])
 
@@ -140,62 +158,3 @@ func (s *IfPipe) traced(n *tracerNode) string {
 
 	return output
 }
-
-func (s *IfPipe) count(ctx context.Context, data interface{}, selectedPath string) {
-	defer s.mtx.Unlock()
-	s.mtx.Lock()
-
-	if s.counters == nil {
-		s.counters = make(map[string]*flowCounter)
-	}
-
-	var (
-		counter *flowCounter
-		ok      bool
-	)
-
-	if counter, ok = s.counters[selectedPath]; !ok {
-		counter = &flowCounter{tagged: make(map[string]uint64)}
-	}
-
-	if s.TrafficTagger != nil {
-		tag := s.TrafficTagger(ctx, data)
-		counter.tagged[tag]++
-	}
-
-	counter.total++
-	s.counters[selectedPath] = counter
-}
-
-func (s *IfPipe) flowVolume() map[string]flowsPercent {
-	defer s.mtx.Unlock()
-	s.mtx.Lock()
-
-	out := make(map[string]flowsPercent)
-
-	if s.counters == nil {
-		return out
-	}
-
-	var global uint64
-	for _, v := range s.counters {
-		global += v.total
-	}
-
-	for k, v := range s.counters {
-		branchCounter := v.total
-
-		percentMap := flowsPercent{
-			total:  (float32(branchCounter) / float32(global)) * oneHundred,
-			tagged: make(map[string]float32),
-		}
-
-		for kk, vv := range v.tagged {
-			percentMap.tagged[kk] = (float32(vv) / float32(branchCounter)) * oneHundred
-		}
-
-		out[k] = percentMap
-	}
-
-	return out
-}
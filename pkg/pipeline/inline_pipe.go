@@ -0,0 +1,264 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+type (
+	TapFn func(context.Context, interface{}) error
+
+	Map struct {
+		Name        string
+		Resolver    StageFn
+		Middlewares []Middleware
+	}
+
+	Filter struct {
+		Name        string
+		Predicate   IsTrueFn
+		Default     interface{}
+		Middlewares []Middleware
+	}
+
+	Tap struct {
+		Name        string
+		Effect      TapFn
+		Middlewares []Middleware
+	}
+
+	inline interface {
+		Pipe
+		apply(context.Context, interface{}, stopwatch) (interface{}, bool, error)
+	}
+
+	fused []inline
+)
+
+func fuse(flow Flow) Flow {
+	var (
+		out  = make(Flow, 0, len(flow))
+		pipe fused
+	)
+
+	for _, stage := range flow {
+		if i, ok := stage.(inline); ok {
+			pipe = append(pipe, i)
+			continue
+		}
+
+		if len(pipe) > 0 {
+			out = append(out, pipe)
+			pipe = nil
+		}
+
+		out = append(out, stage)
+	}
+
+	if len(pipe) > 0 {
+		out = append(out, pipe)
+	}
+
+	return out
+}
+
+func (f fused) connect(
+	ctx context.Context,
+	in <-chan interface{},
+	b breaker,
+) (
+	<-chan interface{},
+	<-chan error,
+) {
+	out := make(chan interface{}, cap(in))
+	errors := make(chan error, 1)
+	tracers := make([]stopwatch, len(f))
+
+	for idx, pipe := range f {
+		tracers[idx] = traceMe(ctx, pipe)
+	}
+
+	panicProof(
+		func() {
+			consume(ctx, in, out, tracers[0], b, func(ctx context.Context, b breaker, data interface{}) {
+				f.handleInput(ctx, out, errors, tracers, b, data)
+			})
+		},
+		notifyPanicAsError(ctx, errors, b, tracers[0]),
+		closeOutput(out, errors),
+	)
+
+	return out, errors
+}
+
+func (f fused) handleInput(
+	ctx context.Context,
+	out chan interface{},
+	errors chan error,
+	tracers []stopwatch,
+	b breaker,
+	data interface{},
+) {
+	for idx, pipe := range f {
+		tracer := tracers[idx]
+		sCtx := tracer.start(ctx)
+
+		value, next, err := safeApply(sCtx, pipe, data, tracer)
+
+		switch {
+		case err != nil && ctx.Err() != nil:
+			cancel(tracer, err, errors, b)
+		case err != nil:
+			fail(tracer, err, errors, b)
+		}
+
+		tracer.done()
+
+		if err != nil || !next {
+			for _, skipped := range tracers[idx+1:] {
+				skipped.canceled()
+			}
+
+			return
+		}
+
+		data = value
+	}
+
+	emit(ctx, out, data)
+}
+
+func safeApply(
+	ctx context.Context,
+	pipe inline,
+	data interface{},
+	tracer stopwatch,
+) (
+	value interface{},
+	next bool,
+	err error,
+) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic recovered: %+v", p)
+		}
+	}()
+
+	return pipe.apply(ctx, data, tracer)
+}
+
+func (f fused) draw(s Skin) string {
+	var output string
+
+	for _, pipe := range f {
+		output += pipe.draw(s)
+	}
+
+	return output
+}
+
+func (fused) traced(*tracerNode) string {
+	return ""
+}
+
+func (m *Map) connect(ctx context.Context, in <-chan interface{}, b breaker) (<-chan interface{}, <-chan error) {
+	return fused{m}.connect(ctx, in, b)
+}
+
+func (m *Map) apply(ctx context.Context, data interface{}, _ stopwatch) (interface{}, bool, error) {
+	out, err := invoke(ctx, KindResolver, stageName(m.resolver()), m.resolver(), m.Middlewares, data, m.Resolver)
+
+	return out, err == nil, err
+}
+
+func (m *Map) draw(_ Skin) string {
+	return drawShapedStage(m.resolver(), "]")
+}
+
+func (m *Map) traced(n *tracerNode) string {
+	return tracedShapedStage(m.resolver(), "]", n)
+}
+
+func (m *Map) resolver() interface{} {
+	return named(m.Name, m.Resolver)
+}
+
+func (f *Filter) connect(ctx context.Context, in <-chan interface{}, b breaker) (<-chan interface{}, <-chan error) {
+	return fused{f}.connect(ctx, in, b)
+}
+
+func (f *Filter) apply(ctx context.Context, data interface{}, tracer stopwatch) (interface{}, bool, error) {
+	keep, err := invoke(ctx, KindPredicate, stageName(f.resolver()), f.resolver(), f.Middlewares, data, f.Predicate)
+	if err != nil || keep {
+		return data, keep, err
+	}
+
+	tracer.exit()
+	exit(ctx, f.Default)
+
+	return nil, false, nil
+}
+
+func (f *Filter) draw(_ Skin) string {
+	return drawShapedStage(f.resolver(), "<")
+}
+
+func (f *Filter) traced(n *tracerNode) string {
+	return tracedShapedStage(f.resolver(), "<", n)
+}
+
+func (f *Filter) resolver() interface{} {
+	return named(f.Name, f.Predicate)
+}
+
+func (t *Tap) connect(ctx context.Context, in <-chan interface{}, b breaker) (<-chan interface{}, <-chan error) {
+	return fused{t}.connect(ctx, in, b)
+}
+
+func (t *Tap) apply(ctx context.Context, data interface{}, tracer stopwatch) (interface{}, bool, error) {
+	_, err := invoke(ctx, KindEffect, stageName(t.resolver()), t.resolver(), t.Middlewares, data, t.effect)
+	if err != nil {
+		tracer.fail(err)
+	}
+
+	return data, true, nil
+}
+
+func (t *Tap) effect(ctx context.Context, data interface{}) (interface{}, error) {
+	return nil, t.Effect(ctx, data)
+}
+
+func (t *Tap) draw(_ Skin) string {
+	return drawShapedStage(t.resolver(), ">")
+}
+
+func (t *Tap) traced(n *tracerNode) string {
+	return tracedShapedStage(t.resolver(), ">", n)
+}
+
+func (t *Tap) resolver() interface{} {
+	return named(t.Name, t.Effect)
+}
+
+func drawShapedStage(r interface{}, shape string) string {
+	return fmt.Sprintf(": %s %s\n", formattedResolver(r), shape)
+}
+
+func tracedShapedStage(r interface{}, shape string, n *tracerNode) string {
+	var out string
+
+	switch {
+	case n.error != nil:
+		out = drawFailedStage(r, n.error)
+
+	case n.cancelled:
+		out = fmt.Sprintf(": --%s-- %s\n", formattedResolver(r), shape)
+
+	default:
+		out = drawShapedStage(r, shape)
+	}
+
+	out += notesOf(n)
+
+	return out
+}
@@ -0,0 +1,95 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func passThrough(_ context.Context, data interface{}) (interface{}, error) {
+	return data, nil
+}
+
+func TestFusedStageRecordsFailure(t *testing.T) {
+	var (
+		spans  = tracetest.NewSpanRecorder()
+		reader = sdkmetric.NewManualReader()
+		boom   = errors.New("boom")
+	)
+
+	bp := &Pipeline{
+		Name:       "fused",
+		Source:     passThrough,
+		Sink:       passThrough,
+		SpanTracer: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(spans)).Tracer("test"),
+		Meter:      sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)).Meter("test"),
+		Flow: Flow{
+			&Map{Name: "bad", Resolver: func(context.Context, interface{}) (interface{}, error) { return nil, boom }},
+		},
+	}
+
+	if _, err := Run(context.Background(), 1, bp, false); !errors.Is(err, boom) {
+		t.Fatalf("expected %v, got %v", boom, err)
+	}
+
+	var span sdktrace.ReadOnlySpan
+	for _, s := range spans.Ended() {
+		if s.Name() == "bad" {
+			span = s
+		}
+	}
+
+	if span == nil {
+		t.Fatal("no span recorded for the fused stage")
+	}
+
+	if span.Status().Code != codes.Error {
+		t.Errorf("expected span status Error, got %v", span.Status().Code)
+	}
+
+	if len(span.Events()) == 0 || span.Events()[0].Name != "exception" {
+		t.Errorf("expected an error event on the span, got %v", span.Events())
+	}
+
+	if outcome := stageOutcome(t, reader, "bad"); outcome != outcomeError {
+		t.Errorf("expected outcome %q, got %q", outcomeError, outcome)
+	}
+}
+
+func stageOutcome(t *testing.T, reader sdkmetric.Reader, stage string) string {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != "pipeline_stage_duration_seconds" {
+				continue
+			}
+
+			for _, dp := range m.Data.(metricdata.Histogram[float64]).DataPoints {
+				if name, _ := dp.Attributes.Value("stage"); name.AsString() != stage {
+					continue
+				}
+
+				outcome, _ := dp.Attributes.Value(attribute.Key("outcome"))
+
+				return outcome.AsString()
+			}
+		}
+	}
+
+	t.Fatalf("no duration recorded for stage %q", stage)
+
+	return ""
+}
@@ -3,6 +3,7 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
 const (
@@ -10,21 +11,44 @@ const (
 	IteratorParentCtx   ctxKey = "parent.ctx"
 )
 
+const (
+	// Pooled keeps up to MaxP items in flight, starting the next one as soon as any finishes.
+	Pooled Scheduling = iota
+	// Batching runs items in fixed chunks of MaxP, waiting for a whole chunk before starting the next.
+	Batching
+)
+
 type (
 	ctxKey string
 
+	Scheduling int
+
 	JoinerFn func(context.Context, interface{}, []interface{}) (interface{}, error)
 
 	Iterator struct {
-		Name     string
-		MaxP     *int
-		Splitter FanOutFn
-		Stream   Flow
-		Joiner   JoinerFn
-		Tagger   BranchTagger
+		Name       string
+		MaxP       *int
+		Scheduling Scheduling
+		Splitter   FanOutFn
+		Stream     Flow
+		Joiner     JoinerFn
+		Tagger     BranchTagger
+		Retry      *RetryPolicy
+		Timeout    time.Duration
+
+		ErrorPolicy ErrorPolicy
+		Middlewares []Middleware
 	}
 )
 
+func (s Scheduling) String() string {
+	if s == Batching {
+		return "batches"
+	}
+
+	return "pool"
+}
+
 func IteratorParent(ctx context.Context) (interface{}, context.Context) {
 	pCtx, _ := ctx.Value(IteratorParentCtx).(context.Context)
 	return ctx.Value(IteratorParentValue), pCtx
@@ -44,15 +68,9 @@ func (i *Iterator) connect(
 
 	panicProof(
 		func() {
-			select {
-			case data, ok := <-in:
-				if ok {
-					i.handleInput(ctx, out, errors, tracer, b, data)
-				}
-			case <-ctx.Done():
-				tracer.canceled()
-				return
-			}
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				i.handleInput(ctx, out, errors, tracer, b, data)
+			})
 		},
 		notifyPanicAsError(ctx, errors, b, tracer),
 		closeOutput(out, errors),
@@ -71,9 +89,20 @@ func (i *Iterator) handleInput(
 ) {
 	defer tracer.done()
 
-	tracer.start(ctx)
+	ctx = tracer.start(ctx)
 
-	paths, err := i.Splitter(ctx, data)
+	timer := newStageTimer(ctx, i, i.Name, i.Timeout, tracer)
+	defer timer.stop()
+
+	var paths []interface{}
+
+	err := i.Retry.do(timer, tracer, func() (err error) {
+		paths, err = bounded(timer, func() ([]interface{}, error) {
+			return invoke(timer, KindSplitter, i.Name, i.Splitter, i.Middlewares, data, i.Splitter)
+		})
+
+		return err
+	})
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
@@ -84,14 +113,18 @@ func (i *Iterator) handleInput(
 		joined []interface{}
 	)
 
+	sb := b.scope()
+
 	for _, chunk := range chunks {
-		chunkResults, err := i.runFlows(ctx, data, chunk, b)
+		chunkResults, err := bounded(timer, func() ([]interface{}, error) {
+			return i.runFlows(timer, data, chunk, sb)
+		})
 		if err != nil {
-			cancel(tracer, err, errors, b)
+			timer.abort(tracer, err, errors, b)
 			return
 		}
 
-		if isCancelled(len(chunkResults), len(chunk)) {
+		if i.ErrorPolicy.incomplete(len(chunk), len(chunkResults)) {
 			tracer.canceled()
 			return
 		}
@@ -99,13 +132,21 @@ func (i *Iterator) handleInput(
 		joined = append(joined, chunkResults...)
 	}
 
-	merged, err := i.Joiner(ctx, data, joined)
+	joined, err = i.ErrorPolicy.collect(joined)
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
 	}
 
-	out <- merged
+	merged, err := bounded(timer, func() (interface{}, error) {
+		return invoke(timer, KindJoiner, i.Name, i.Joiner, i.Middlewares, joined, joinWith(data, i.Joiner))
+	})
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	emit(ctx, out, merged)
 }
 
 func (i *Iterator) runFlows(
@@ -118,33 +159,31 @@ func (i *Iterator) runFlows(
 	error,
 ) {
 	var (
-		pathOuts = make([]<-chan interface{}, len(paths))
-		pathErrs []<-chan error
+		branches = make([]branch, len(paths))
 		flowCtx  context.Context
 	)
 
 	for idx, pathData := range paths {
-		pathIn := make(chan interface{}, 1)
-
 		txnName := fmt.Sprintf("%s#%v", i.Name, idx)
 		flowCtx = CtxBranch(ctx, txnName)
 		flowCtx = context.WithValue(flowCtx, IteratorParentValue, data)
 		flowCtx = context.WithValue(flowCtx, IteratorParentCtx, ctx)
-		tagger := i.Tagger(flowCtx, pathData)
-		flowCtx = openBranch(flowCtx, i, tagger)
+		flowCtx = openBranch(flowCtx, i, i.branchName(flowCtx, pathData, idx))
+
+		branches[idx] = branch{ctx: flowCtx, flow: i.Stream, data: pathData}
+	}
 
-		pathOut, ferr := connectFlow(flowCtx, pathIn, i.Stream, b)
+	recordFanOut(ctx, i, len(branches))
 
-		pathOuts[idx] = pathOut
-		pathErrs = append(pathErrs, ferr...)
+	return i.ErrorPolicy.run(ctx, branches, b, i.workers())
+}
 
-		go feed(flowCtx, pathIn, pathData)
+func (i *Iterator) branchName(ctx context.Context, pathData interface{}, idx int) string {
+	if i.Tagger == nil {
+		return fmt.Sprintf("#%d", idx+1)
 	}
 
-	return mergeAll(
-		func() <-chan interface{} { return fanIn(ctx, pathOuts...) },
-		pathErrs,
-	)
+	return i.Tagger(ctx, pathData)
 }
 
 func (i *Iterator) draw(s Skin) string {
@@ -155,7 +194,18 @@ func (i *Iterator) draw(s Skin) string {
 	}
 
 	output += "endfork \n"
-	output += fmt.Sprintf("note right\n<font size=\"24\">%s</font>\nend note\n", i.Name)
+	output += "note right\n"
+	output += fmt.Sprintf("<font size=\"24\">%s</font>\n", i.Name)
+
+	if i.MaxP != nil && *i.MaxP > 0 {
+		output += fmt.Sprintf("//%s of %d//\n", i.Scheduling, *i.MaxP)
+	}
+
+	if policy := policyNote(i.ErrorPolicy); policy != "" {
+		output += policy + "\n"
+	}
+
+	output += "end note\n"
 
 	return output
 }
@@ -170,6 +220,7 @@ func (i *Iterator) traced(n *tracerNode) string {
 		output += "fork \n"
 		output += fmt.Sprintf(": ☠ %+v; \n", n.error)
 		output += "endfork \n"
+		output += attemptsOf(n)
 		output += notesOf(n)
 
 		return output
@@ -189,13 +240,22 @@ func (i *Iterator) traced(n *tracerNode) string {
 	}
 
 	output += "endfork \n"
+	output += attemptsOf(n)
 	output += notesOf(n)
 
 	return output
 }
 
+func (i *Iterator) workers() int {
+	if i.MaxP == nil || i.Scheduling == Batching {
+		return 0
+	}
+
+	return *i.MaxP
+}
+
 func (i *Iterator) chunks(paths []interface{}) [][]interface{} {
-	if i.MaxP == nil {
+	if i.MaxP == nil || i.Scheduling != Batching {
 		return [][]interface{}{paths}
 	}
 
@@ -0,0 +1,75 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// benchmarkIterator fans out items where one in every maxP is slow, the case where Batching waits on the straggler of
+// each chunk while Pooled keeps the other workers busy.
+func benchmarkIterator(b *testing.B, scheduling Scheduling) {
+	const (
+		items = 32
+		maxP  = 4
+	)
+
+	workers := maxP
+
+	bp := &Pipeline{
+		Name:   "iterator",
+		Source: passThrough,
+		Sink:   passThrough,
+		Flow: Flow{
+			&Iterator{
+				Name:       "skewed",
+				MaxP:       &workers,
+				Scheduling: scheduling,
+				Splitter: func(context.Context, interface{}) ([]interface{}, error) {
+					values := make([]interface{}, items)
+					for i := range values {
+						values[i] = i
+					}
+
+					return values, nil
+				},
+				Stream: Flow{
+					Stage(func(_ context.Context, data interface{}) (interface{}, error) {
+						delay := 100 * time.Microsecond
+						if data.(int)%maxP == 0 {
+							delay = 2 * time.Millisecond
+						}
+
+						time.Sleep(delay)
+
+						return data, nil
+					}),
+				},
+				Joiner: func(_ context.Context, _ interface{}, joined []interface{}) (interface{}, error) {
+					return len(joined), nil
+				},
+			},
+		},
+	}
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		out, err := Run(context.Background(), nil, bp, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		if out != items {
+			b.Fatalf("expected %d joined items, got %v", items, out)
+		}
+	}
+}
+
+func BenchmarkIterator_Pooled(b *testing.B) {
+	benchmarkIterator(b, Pooled)
+}
+
+func BenchmarkIterator_Batching(b *testing.B) {
+	benchmarkIterator(b, Batching)
+}
@@ -0,0 +1,140 @@
+package pipeline
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// LinkKeysEnv lists the link keys as comma separated id=hex pairs, e.g. "2024-06=00112233...,2024-01=44556677...".
+	LinkKeysEnv = "PIPELINE_LINK_KEYS"
+	// LinkKeyIDEnv names the key used to encrypt new links; it defaults to the first key listed in LinkKeysEnv.
+	LinkKeyIDEnv = "PIPELINE_LINK_KEY_ID"
+)
+
+var (
+	ErrNoLinkKey      = errors.New("no diagram link key configured")
+	ErrUnknownLinkKey = errors.New("unknown diagram link key")
+)
+
+type (
+	// KeyProvider hands out the AES keys used by encrypted diagram links. The active key encrypts new
+	// links while older keys stay resolvable by ID, so keys can be rotated without breaking shared links.
+	KeyProvider interface {
+		ActiveKey() (id string, key []byte, err error)
+		Key(id string) ([]byte, error)
+	}
+
+	// StaticKeys is a KeyProvider backed by configuration.
+	StaticKeys struct {
+		Active string
+		Keys   map[string][]byte
+	}
+
+	envKeys struct{}
+)
+
+var Keys KeyProvider = EnvKeys()
+
+func EnvKeys() KeyProvider {
+	return envKeys{}
+}
+
+func (s *StaticKeys) ActiveKey() (string, []byte, error) {
+	if s.Active == "" {
+		return "", nil, ErrNoLinkKey
+	}
+
+	key, err := s.Key(s.Active)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return s.Active, key, nil
+}
+
+func (s *StaticKeys) Key(id string) ([]byte, error) {
+	key, ok := s.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownLinkKey, id)
+	}
+
+	return key, nil
+}
+
+func (envKeys) ActiveKey() (string, []byte, error) {
+	keys, first, err := envKeys{}.load()
+	if err != nil {
+		return "", nil, err
+	}
+
+	active := os.Getenv(LinkKeyIDEnv)
+	if active == "" {
+		active = first
+	}
+
+	return (&StaticKeys{Active: active, Keys: keys}).ActiveKey()
+}
+
+func (envKeys) Key(id string) ([]byte, error) {
+	keys, _, err := envKeys{}.load()
+	if err != nil {
+		return nil, err
+	}
+
+	return (&StaticKeys{Keys: keys}).Key(id)
+}
+
+func (envKeys) load() (map[string][]byte, string, error) {
+	var (
+		keys  = make(map[string][]byte)
+		first string
+	)
+
+	for _, pair := range strings.Split(os.Getenv(LinkKeysEnv), ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, raw, ok := strings.Cut(pair, "=")
+		if !ok || !validKeyID(id) {
+			return nil, "", fmt.Errorf("%s: malformed entry %q", LinkKeysEnv, id)
+		}
+
+		key, err := hex.DecodeString(raw)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: key %q is not hex: %w", LinkKeysEnv, id, err)
+		}
+
+		if first == "" {
+			first = id
+		}
+
+		keys[id] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, "", ErrNoLinkKey
+	}
+
+	return keys, first, nil
+}
+
+// validKeyID keeps key IDs inside the PlantUML base64 alphabet, so they never clash with the link separators.
+func validKeyID(id string) bool {
+	if id == "" {
+		return false
+	}
+
+	for _, c := range id {
+		if !strings.ContainsRune(mapper, c) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,119 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const latencyKey ctxKey = "pipeline.latency"
+
+type (
+	latency struct {
+		mtx    sync.Mutex
+		stages map[Traceable]*observed
+	}
+
+	observed struct {
+		count uint64
+		total time.Duration
+		max   time.Duration
+	}
+
+	timedTask struct {
+		stopwatch
+		latency *latency
+		pipe    Traceable
+
+		mtx     sync.Mutex
+		started time.Time
+		aborted bool
+	}
+)
+
+func withLatency(ctx context.Context, bp *Pipeline) context.Context {
+	return context.WithValue(ctx, latencyKey, &bp.latency)
+}
+
+func timed(ctx context.Context, pipe Traceable, task stopwatch) stopwatch {
+	l, ok := ctx.Value(latencyKey).(*latency)
+	if !ok {
+		return task
+	}
+
+	return &timedTask{stopwatch: task, latency: l, pipe: pipe}
+}
+
+func (t *timedTask) start(ctx context.Context) context.Context {
+	ctx = t.stopwatch.start(ctx)
+
+	t.mtx.Lock()
+	t.started, t.aborted = now(), false
+	t.mtx.Unlock()
+
+	return ctx
+}
+
+func (t *timedTask) canceled() {
+	t.stopwatch.canceled()
+
+	t.mtx.Lock()
+	t.aborted = true
+	t.mtx.Unlock()
+}
+
+func (t *timedTask) done() {
+	t.stopwatch.done()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.started.IsZero() || t.aborted {
+		return
+	}
+
+	t.latency.observe(t.pipe, time.Since(t.started))
+	t.started = time.Time{}
+}
+
+func (l *latency) observe(pipe Traceable, elapsed time.Duration) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.stages == nil {
+		l.stages = make(map[Traceable]*observed)
+	}
+
+	o, ok := l.stages[pipe]
+	if !ok {
+		o = &observed{}
+		l.stages[pipe] = o
+	}
+
+	o.count++
+	o.total += elapsed
+
+	if elapsed > o.max {
+		o.max = elapsed
+	}
+}
+
+func (l *latency) of(pipe Traceable) (observed, bool) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	o, ok := l.stages[pipe]
+	if !ok {
+		return observed{}, false
+	}
+
+	return *o, true
+}
+
+func (o observed) mean() time.Duration {
+	if o.count == 0 {
+		return 0
+	}
+
+	return o.total / time.Duration(o.count)
+}
@@ -2,28 +2,107 @@ package pipeline
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/zlib"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
+	"strings"
 )
 
-func Link(p *Pipeline) string {
-	return fmt.Sprint(RenderServer, Encoded(p.Diagram()))
+var ErrInvalidLink = errors.New("invalid diagram link")
+
+func Link(p *Pipeline) (string, error) {
+	return link(p.Diagram())
+}
+
+func link(diagram string) (string, error) {
+	encoded, err := Encoded(diagram)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprint(RenderServer, encoded), nil
 }
 
-func Encoded(diagram string) string {
+// Encoded follows the PlantUML text encoding (deflate + PlantUML base64) unless EncryptedMode is on,
+// in which case the payload is sealed with the active key from Keys and tagged with that key's ID.
+// With no key configured at all the link falls back to the plain encoding.
+func Encoded(diagram string) (string, error) {
 	raw := []byte(diagram)
-	compressed := deflate(raw)
 
-	return base64Encode(compressed)
+	if EncryptedMode {
+		encrypted, err := encrypt(compress(raw))
+		if !errors.Is(err, ErrNoLinkKey) {
+			return encrypted, err
+		}
+	}
+
+	return base64Encode(deflate(raw)), nil
+}
+
+// Decode reverses Link, TracedLink and Encoded, returning the diagram source.
+func Decode(link string) (string, error) {
+	encoded := link[strings.LastIndex(link, "/")+1:]
+	if encoded == "" {
+		return "", ErrInvalidLink
+	}
+
+	reader, err := inflater(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	defer reader.Close()
+
+	diagram, err := io.ReadAll(reader)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidLink, err)
+	}
+
+	return string(diagram), nil
+}
+
+func inflater(encoded string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(encoded, encryptedPrefix) {
+		compressed, err := base64Decode(encoded)
+		if err != nil {
+			return nil, err
+		}
+
+		return flate.NewReader(bytes.NewReader(compressed)), nil
+	}
+
+	compressed, err := decrypt(strings.TrimPrefix(encoded, encryptedPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLink, err)
+	}
+
+	return reader, nil
 }
 
 func deflate(input []byte) []byte {
 	var b bytes.Buffer
 
+	w, _ := flate.NewWriter(&b, flate.BestCompression)
+	_, _ = w.Write(input)
+	_ = w.Close()
+
+	return b.Bytes()
+}
+
+func compress(input []byte) []byte {
+	var b bytes.Buffer
+
 	w, _ := zlib.NewWriterLevel(&b, zlib.BestCompression)
 	_, _ = w.Write(input)
 	_ = w.Close()
@@ -53,26 +132,109 @@ func base64Encode(input []byte) string {
 		}
 	}
 
-	return encrypt(buffer)
+	return buffer.String()
+}
+
+// base64Decode accepts a trailing partial group, as PlantUML encoders don't pad their output.
+func base64Decode(input string) ([]byte, error) {
+	if len(input)%4 == 1 {
+		return nil, ErrInvalidLink
+	}
+
+	out := make([]byte, 0, len(input)*3/4)
+
+	for i := 0; i < len(input); i += 4 {
+		var (
+			c     [4]byte
+			group = input[i:min(i+4, len(input))]
+		)
+
+		for j := range group {
+			idx := strings.IndexByte(mapper, group[j])
+			if idx < 0 {
+				return nil, ErrInvalidLink
+			}
+
+			c[j] = byte(idx)
+		}
+
+		decoded := []byte{
+			c[0]<<2 | c[1]>>4,
+			(c[1]&hFifteen)<<4 | c[2]>>2,
+			(c[2]&hThree)<<6 | c[3],
+		}
+
+		out = append(out, decoded[:len(group)-1]...)
+	}
+
+	return out, nil
 }
 
-func encrypt(buffer bytes.Buffer) string {
-	plaintext := buffer.Bytes()
+func encrypt(plaintext []byte) (string, error) {
+	id, key, err := Keys.ActiveKey()
+	if err != nil {
+		return "", err
+	}
 
-	block, err := aes.NewCipher(key)
+	if !validKeyID(id) {
+		return "", fmt.Errorf("%w: %q", ErrUnknownLinkKey, id)
+	}
+
+	aead, err := gcm(key)
 	if err != nil {
-		panic(err)
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
 	}
 
-	ciphertext := make([]byte, aes.BlockSize+len(plaintext))
-	iv := ciphertext[:aes.BlockSize]
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(id))
+
+	return encryptedPrefix + id + keySeparator + hex.EncodeToString(sealed), nil
+}
 
-	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
-		panic(err)
+func decrypt(encrypted string) ([]byte, error) {
+	id, payload, ok := strings.Cut(encrypted, keySeparator)
+	if !ok {
+		return nil, ErrInvalidLink
 	}
 
-	stream := cipher.NewCFBEncrypter(block, iv)
-	stream.XORKeyStream(ciphertext[aes.BlockSize:], plaintext)
+	sealed, err := hex.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+
+	key, err := Keys.Key(id)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := gcm(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidLink
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidLink, err)
+	}
+
+	return plaintext, nil
+}
+
+func gcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
 
-	return fmt.Sprintf("%x", ciphertext)
+	return cipher.NewGCM(block)
 }
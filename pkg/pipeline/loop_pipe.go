@@ -3,6 +3,8 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 )
 
 type (
@@ -12,9 +14,28 @@ type (
 		Stream   Flow
 		Joiner   JoinerFn
 		Tagger   BranchTagger
+		Retry    *RetryPolicy
+		Timeout  time.Duration
+
+		// While turns the loop into a do-while over Stream: each iteration feeds the previous output back in
+		// until While returns false. Splitter and Retry are ignored in this mode; Timeout bounds the whole loop.
+		While IsTrueFn
+		// MaxIterations caps a While loop; reaching it fails the stage with *ErrMaxIterations instead of
+		// emitting the last state.
+		MaxIterations int
+		Middlewares   []Middleware
+	}
+
+	ErrMaxIterations struct {
+		Stage string
+		Max   int
 	}
 )
 
+func (e *ErrMaxIterations) Error() string {
+	return fmt.Sprintf("loop %s still running after %d iterations", e.Stage, e.Max)
+}
+
 func (l *Loop) connect(ctx context.Context, in <-chan interface{}, b breaker) (<-chan interface{}, <-chan error) {
 	out := make(chan interface{}, 1)
 	errors := make(chan error, 1)
@@ -22,15 +43,9 @@ func (l *Loop) connect(ctx context.Context, in <-chan interface{}, b breaker) (<
 
 	panicProof(
 		func() {
-			select {
-			case data, ok := <-in:
-				if ok {
-					l.handleInput(ctx, out, errors, tracer, b, data)
-				}
-			case <-ctx.Done():
-				tracer.canceled()
-				return
-			}
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				l.handleInput(ctx, out, errors, tracer, b, data)
+			})
 		},
 		notifyPanicAsError(ctx, errors, b, tracer),
 		closeOutput(out, errors),
@@ -49,17 +64,37 @@ func (l *Loop) handleInput(
 ) {
 	defer tracer.done()
 
-	tracer.start(ctx)
+	ctx = tracer.start(ctx)
+
+	timer := newStageTimer(ctx, l, l.Name, l.Timeout, tracer)
+	defer timer.stop()
+
+	if l.While != nil {
+		l.iterate(ctx, timer, out, errors, tracer, b, data)
+		return
+	}
+
+	var values []interface{}
+
+	err := l.Retry.do(timer, tracer, func() (err error) {
+		values, err = bounded(timer, func() ([]interface{}, error) {
+			return invoke(timer, KindSplitter, l.Name, l.Splitter, l.Middlewares, data, l.Splitter)
+		})
 
-	values, err := l.Splitter(ctx, data)
+		return err
+	})
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
 	}
 
-	joined, err := l.runFlow(ctx, data, values, b)
+	sb := b.scope()
+
+	joined, err := bounded(timer, func() ([]interface{}, error) {
+		return l.runFlow(timer, data, values, sb)
+	})
 	if err != nil {
-		cancel(tracer, err, errors, b)
+		timer.abort(tracer, err, errors, b)
 		return
 	}
 
@@ -68,13 +103,15 @@ func (l *Loop) handleInput(
 		return
 	}
 
-	merged, err := l.Joiner(ctx, data, joined)
+	merged, err := bounded(timer, func() (interface{}, error) {
+		return invoke(timer, KindJoiner, l.Name, l.Joiner, l.Middlewares, joined, joinWith(data, l.Joiner))
+	})
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
 	}
 
-	out <- merged
+	emit(ctx, out, merged)
 }
 
 func (l *Loop) runFlow(
@@ -95,12 +132,7 @@ func (l *Loop) runFlow(
 	for idx, pathData := range values {
 		pathIn := make(chan interface{}, 1)
 
-		txnName := fmt.Sprintf("%s#%v", l.Name, idx)
-		flowCtx = CtxBranch(ctx, txnName)
-		flowCtx = context.WithValue(flowCtx, IteratorParentValue, data)
-		flowCtx = context.WithValue(flowCtx, IteratorParentCtx, ctx)
-		tagger := l.Tagger(flowCtx, pathData)
-		flowCtx = openBranch(flowCtx, l, tagger)
+		flowCtx = l.branch(ctx, data, pathData, idx)
 
 		pathOut, ferr := connectFlow(flowCtx, pathIn, l.Stream, b)
 
@@ -120,6 +152,93 @@ func (l *Loop) runFlow(
 	)
 }
 
+func (l *Loop) iterate(
+	ctx context.Context,
+	timer *stageTimer,
+	out chan interface{},
+	errors chan error,
+	tracer stopwatch,
+	b breaker,
+	data interface{},
+) {
+	sb := b.scope()
+
+	var (
+		state   = data
+		outputs []interface{}
+	)
+
+	for idx := 0; ; idx++ {
+		if l.MaxIterations > 0 && idx >= l.MaxIterations {
+			fail(tracer, &ErrMaxIterations{Stage: l.Name, Max: l.MaxIterations}, errors, b)
+			return
+		}
+
+		flowCtx := l.branch(timer, data, state, idx)
+
+		r, err := bounded(timer, func() (Result, error) {
+			r, ok := runOn(flowCtx, branch{ctx: flowCtx, flow: l.Stream, data: state}, sb)
+			if !ok && r.Err == nil {
+				r.Err = context.Canceled
+			}
+
+			return r, r.Err
+		})
+		if err != nil {
+			timer.abort(tracer, err, errors, b)
+			return
+		}
+
+		state = r.Value
+		outputs = append(outputs, state)
+
+		again, err := bounded(timer, func() (bool, error) {
+			return invoke(flowCtx, KindCondition, l.Name, l.While, l.Middlewares, state, l.While)
+		})
+		if err != nil {
+			fail(tracer, err, errors, b)
+			return
+		}
+
+		if !again {
+			break
+		}
+	}
+
+	if l.Joiner == nil {
+		emit(ctx, out, state)
+		return
+	}
+
+	joined, err := bounded(timer, func() (interface{}, error) {
+		return invoke(timer, KindJoiner, l.Name, l.Joiner, l.Middlewares, outputs, joinWith(data, l.Joiner))
+	})
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	emit(ctx, out, joined)
+}
+
+func (l *Loop) branch(ctx context.Context, data, pathData interface{}, idx int) context.Context {
+	txnName := fmt.Sprintf("%s#%v", l.Name, idx)
+	flowCtx := CtxBranch(ctx, txnName)
+	flowCtx = context.WithValue(flowCtx, IteratorParentValue, data)
+	flowCtx = context.WithValue(flowCtx, IteratorParentCtx, ctx)
+
+	if l.Tagger == nil {
+		return openBranch(flowCtx, l, fmt.Sprintf("#%d", idx+1))
+	}
+
+	tagger := l.Tagger(flowCtx, pathData)
+	if l.While != nil {
+		tagger = fmt.Sprintf("#%d %s", idx+1, tagger)
+	}
+
+	return openBranch(flowCtx, l, tagger)
+}
+
 func (l *Loop) draw(s Skin) string {
 	output := "repeat\n"
 
@@ -127,31 +246,62 @@ func (l *Loop) draw(s Skin) string {
 		output += pipe.draw(s)
 	}
 
-	output += "repeat while \n"
+	if l.While == nil {
+		output += "repeat while \n"
+		return output
+	}
+
+	output += fmt.Sprintf("repeat while (%s?) is (yes) not (no)\n", l.Name)
+
+	if l.MaxIterations > 0 {
+		output += fmt.Sprintf("note right\n//max %d iterations//\nend note\n", l.MaxIterations)
+	}
 
 	return output
 }
 
 func (l *Loop) traced(n *tracerNode) string {
 	var (
-		output string
+		output     string
+		iterations = n.branches.dump()
 	)
 
+	for _, name := range chronological(iterations) {
+		output += fmt.Sprintf(":%s }\n", fontMultiline(name, "<font color=\"$traceTagColor\">"))
+		output += traceBranch(iterations[name])
+	}
+
 	if n.error != nil {
 		output += "repeat \n"
 		output += fmt.Sprintf(": ☠ %+v; \n", n.error)
 		output += "repeat while \n"
-		output += notesOf(n)
-
-		return output
-	}
-
-	for name, b := range n.branches.dump() {
-		output += fmt.Sprintf(":%s }\n", fontMultiline(name, "<font color=\"$traceTagColor\">"))
-		output += traceBranch(b)
 	}
 
+	output += attemptsOf(n)
 	output += notesOf(n)
 
 	return output
 }
+
+func chronological(branches map[string][]*tracerNode) []string {
+	var (
+		names  = make([]string, 0, len(branches))
+		starts = make(map[string]time.Time, len(branches))
+	)
+
+	for name, nodes := range branches {
+		names = append(names, name)
+
+		if len(nodes) > 0 {
+			nodes[0].mtx.Lock()
+			starts[name] = nodes[0].startTime
+			nodes[0].mtx.Unlock()
+		}
+	}
+
+	sort.SliceStable(names, func(i, j int) bool {
+		return starts[names[i]].Before(starts[names[j]])
+	})
+
+	return names
+}
@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestLoopWhileIgnoresSplitterAndRetry(t *testing.T) {
+	var (
+		splits   atomic.Int32
+		attempts atomic.Int32
+		boom     = errors.New("boom")
+	)
+
+	loop := func(failFirst bool) *Pipeline {
+		return &Pipeline{
+			Name:   "loop",
+			Source: passThrough,
+			Sink:   passThrough,
+			Flow: Flow{
+				&Loop{
+					Name: "count",
+					Splitter: func(_ context.Context, data interface{}) ([]interface{}, error) {
+						splits.Add(1)
+						return []interface{}{data}, nil
+					},
+					Retry: &RetryPolicy{MaxAttempts: 3},
+					Stream: Flow{
+						Stage(func(_ context.Context, data interface{}) (interface{}, error) {
+							if attempts.Add(1) == 1 && failFirst {
+								return nil, boom
+							}
+
+							return data.(int) + 1, nil
+						}),
+					},
+					While: func(_ context.Context, data interface{}) (bool, error) {
+						return data.(int) < 3, nil
+					},
+				},
+			},
+		}
+	}
+
+	out, err := Run(context.Background(), 0, loop(false), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out != 3 {
+		t.Errorf("expected 3, got %v", out)
+	}
+
+	if n := splits.Load(); n != 0 {
+		t.Errorf("expected the Splitter to be ignored, called %d times", n)
+	}
+
+	attempts.Store(0)
+
+	if _, err := Run(context.Background(), 0, loop(true), false); !errors.Is(err, boom) {
+		t.Errorf("expected the first failure %v without retries, got %v", boom, err)
+	}
+
+	if n := attempts.Load(); n != 1 {
+		t.Errorf("expected a single attempt, got %d", n)
+	}
+}
+
+func TestLoopMaxIterationsFailsStage(t *testing.T) {
+	bp := &Pipeline{
+		Name:   "loop",
+		Source: passThrough,
+		Sink:   passThrough,
+		Flow: Flow{
+			&Loop{
+				Name:          "forever",
+				Stream:        Flow{Stage(passThrough)},
+				While:         func(context.Context, interface{}) (bool, error) { return true, nil },
+				MaxIterations: 4,
+			},
+		},
+	}
+
+	var max *ErrMaxIterations
+	if _, err := Run(context.Background(), 0, bp, false); !errors.As(err, &max) || max.Max != 4 {
+		t.Errorf("expected *ErrMaxIterations after 4 iterations, got %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	mmStage    = "%s[\"%s\"]"
+	mmMap      = "%s[/\"%s\"/]"
+	mmFilter   = "%s{\"%s\"}"
+	mmTap      = "%s>\"%s\"]"
+	mmDecision = "%s{\"%s\"}"
+	mmFork     = "%s{{\"%s\"}}"
+	mmMerge    = "%s[\\\"%s\"/]"
+	mmJunction = "%s((\"%s\"))"
+	mmTerminal = "%s([\"%s\"])"
+	mmLinked   = "%s[[\"%s\"]]"
+
+	mmClasses = "classDef failed fill:#fde2e1,stroke:#b3261e,color:#b3261e\n" +
+		"classDef canceled fill:#eeeeee,stroke:#9e9e9e,color:#757575,stroke-dasharray:4 3\n" +
+		"classDef exited stroke:#e07b00,stroke-width:2px\n"
+)
+
+type (
+	mermaid struct{}
+
+	mermaidGraph struct {
+		out strings.Builder
+		ids int
+	}
+
+	// openEdge is a node waiting for its outgoing edge, along with the label that edge should carry.
+	openEdge struct {
+		id    string
+		label string
+	}
+)
+
+func (mermaid) Blueprint(p *Pipeline) string {
+	g := &mermaidGraph{}
+
+	g.header(p.Name, p.Description)
+	g.blueprintOf(nil, p)
+	g.line(mmClasses)
+
+	return g.out.String()
+}
+
+func (mermaid) Trace(ctx context.Context) string {
+	t, ok := ctx.Value(tracerKey).(*tracer)
+	if !ok {
+		return ""
+	}
+
+	t.finish()
+
+	g := &mermaidGraph{}
+	g.header(t.name, fmt.Sprintf("txn: %s", TxnID(ctx)))
+
+	src := g.node(mmTerminal, "source", "")
+	ends := []openEdge{{id: src}}
+
+	if t.sourceExited {
+		ends[0].label = "⏏ early exit"
+	}
+
+	ends = g.traceBranch(ends, t.nodes)
+
+	sink := g.node(mmTerminal, fmt.Sprintf("sink<br/>total %.4fms", elapsedTime(t.start, t.end)), "")
+	g.link(ends, sink)
+	g.line(mmClasses)
+
+	return g.out.String()
+}
+
+func (g *mermaidGraph) header(title, subtitle string) {
+	if subtitle != "" {
+		title += " · " + subtitle
+	}
+
+	g.line("---")
+	g.line(fmt.Sprintf("title: %s", mermaidText(title)))
+	g.line("---")
+	g.line("flowchart TD")
+}
+
+func (g *mermaidGraph) line(s string) {
+	g.out.WriteString(s)
+
+	if !strings.HasSuffix(s, "\n") {
+		g.out.WriteString("\n")
+	}
+}
+
+func (g *mermaidGraph) node(shape, label, class string) string {
+	g.ids++
+
+	id := fmt.Sprintf("n%d", g.ids)
+	g.line(fmt.Sprintf(shape, id, mermaidText(label)))
+
+	if class != "" {
+		g.line(fmt.Sprintf("class %s %s", id, class))
+	}
+
+	return id
+}
+
+func (g *mermaidGraph) link(from []openEdge, to string) {
+	for _, f := range from {
+		if f.label == "" {
+			g.line(fmt.Sprintf("%s --> %s", f.id, to))
+			continue
+		}
+
+		g.line(fmt.Sprintf("%s -->|\"%s\"| %s", f.id, mermaidText(f.label), to))
+	}
+}
+
+// step adds a node and wires every pending end into it.
+func (g *mermaidGraph) step(from []openEdge, shape, label, class string) []openEdge {
+	id := g.node(shape, label, class)
+	g.link(from, id)
+
+	return []openEdge{{id: id}}
+}
+
+func (g *mermaidGraph) blueprintOf(from []openEdge, p *Pipeline) []openEdge {
+	ends := g.step(from, mmTerminal, mermaidName(named(p.SourceName, p.Source), "source"), "")
+	ends = g.flow(ends, p.Flow)
+
+	return g.step(ends, mmTerminal, mermaidName(named(p.SinkName, p.Sink), "sink"), "")
+}
+
+func (g *mermaidGraph) flow(from []openEdge, flow Flow) []openEdge {
+	for _, pipe := range flow {
+		from = g.pipe(from, pipe)
+	}
+
+	return from
+}
+
+// branches draws each flow out of the fork node and returns the loose ends of all of them.
+func (g *mermaidGraph) branches(fork string, labels []string, flows []Flow) []openEdge {
+	var ends []openEdge
+
+	for i, flow := range flows {
+		ends = append(ends, g.flow([]openEdge{{id: fork, label: labels[i]}}, flow)...)
+	}
+
+	return ends
+}
+
+func (g *mermaidGraph) pipe(from []openEdge, pipe Pipe) []openEdge {
+	stage, _ := describe(pipe)
+
+	switch p := pipe.(type) {
+	case *SimplePipe:
+		return g.step(from, mmStage, stage, "")
+	case *Map:
+		return g.step(from, mmMap, stage, "")
+	case *Filter:
+		return g.step(from, mmFilter, stage, "")
+	case *Tap:
+		return g.step(from, mmTap, stage, "")
+
+	case *Broadcast:
+		fork := g.step(from, mmFork, p.Name, "")[0].id
+		ends := g.branches(fork, make([]string, len(p.Streams)), p.Streams)
+
+		return g.step(ends, mmMerge, mermaidName(p.Merger, "merge"), "")
+
+	case *Race:
+		label := "first wins"
+		if p.Hedge > 0 {
+			label = fmt.Sprintf("hedged every %v", p.Hedge)
+		}
+
+		fork := g.step(from, mmFork, p.Name, "")[0].id
+		ends := g.branches(fork, make([]string, len(p.Streams)), p.Streams)
+
+		return g.step(ends, mmJunction, label, "")
+
+	case *Quorum:
+		fork := g.step(from, mmFork, p.Name, "")[0].id
+		ends := g.branches(fork, make([]string, len(p.Streams)), p.Streams)
+
+		return g.step(ends, mmMerge, fmt.Sprintf("%s<br/>%d of %d", mermaidName(p.Merger, "merge"), p.required(), len(p.Streams)), "")
+
+	case *Iterator:
+		label := p.Name
+		if p.MaxP != nil && *p.MaxP > 0 {
+			label += fmt.Sprintf("<br/>%s of %d", p.Scheduling, *p.MaxP)
+		}
+
+		fork := g.step(from, mmFork, label, "")[0].id
+		ends := g.branches(fork, []string{"each"}, []Flow{p.Stream})
+
+		return g.step(ends, mmMerge, mermaidName(p.Joiner, "join"), "")
+
+	case *Loop:
+		entry := g.step(from, mmJunction, "↻ "+p.Name, "")
+		ends := g.flow(entry, p.Stream)
+
+		if p.While == nil {
+			return ends
+		}
+
+		label := p.Name + "?"
+		if p.MaxIterations > 0 {
+			label += fmt.Sprintf("<br/>max %d", p.MaxIterations)
+		}
+
+		decision := g.step(ends, mmDecision, label, "")[0].id
+		g.link([]openEdge{{id: decision, label: "yes"}}, entry[0].id)
+
+		return []openEdge{{id: decision, label: "no"}}
+
+	case *IfPipe:
+		volume := p.traffic.flowVolume(p.TrafficWindow)
+		decision := g.step(from, mmDecision, p.Name+"?", "")[0].id
+
+		return g.branches(
+			decision,
+			[]string{withVolume("yes", volume["true"]), withVolume("no", volume["false"])},
+			[]Flow{p.TrueFlow, p.FalseFlow},
+		)
+
+	case *Switch:
+		volume := p.traffic.flowVolume(p.TrafficWindow)
+		decision := g.step(from, mmDecision, p.Name+"?", "")[0].id
+
+		var labels []string
+		for _, k := range sortedKeys(p.Cases) {
+			labels = append(labels, withVolume(k, volume[k]))
+		}
+
+		labels = append(labels, withVolume(caseName(defaultCase), volume[defaultCase]))
+
+		return g.branches(decision, labels, append(sortedFlows(p.Cases), p.Default))
+
+	case *PartitionPipe:
+		volume := p.traffic.flowVolume(p.TrafficWindow)
+		fork := g.step(from, mmFork, p.Name, "")[0].id
+
+		var labels []string
+		for _, k := range sortedKeys(p.Paths) {
+			labels = append(labels, withVolume(k, volume[k]))
+		}
+
+		ends := g.branches(fork, labels, sortedFlows(p.Paths))
+
+		return g.step(ends, mmMerge, mermaidName(p.Merger, "merge"), "")
+
+	case *SubPipeline:
+		sp := p.pipeline()
+
+		if p.Linked {
+			return g.step(from, mmLinked, "⧉ "+sp.Name, "")
+		}
+
+		g.ids++
+		g.line(fmt.Sprintf("subgraph s%d [\"%s\"]", g.ids, mermaidText(sp.Name)))
+		ends := g.blueprintOf(from, sp)
+		g.line("end")
+
+		return ends
+
+	default:
+		return g.step(from, mmStage, stage, "")
+	}
+}
+
+func (g *mermaidGraph) traceBranch(from []openEdge, nodes []*tracerNode) []openEdge {
+	for _, node := range nodes {
+		node.mtx.Lock()
+		from = g.traced(from, node)
+		node.mtx.Unlock()
+	}
+
+	return from
+}
+
+func (g *mermaidGraph) traced(from []openEdge, n *tracerNode) []openEdge {
+	var (
+		stage, kind = describe(n.pipe)
+		label       = stage + "<br/>" + tracedTiming(n)
+		class       = tracedClass(n)
+		branches    = n.branches.dump()
+	)
+
+	if n.error != nil {
+		label += "<br/>☠ " + n.error.Error()
+	}
+
+	switch kind {
+	case "simple":
+		return g.step(from, mmStage, label, class)
+	case "map":
+		return g.step(from, mmMap, label, class)
+	case "filter":
+		return g.step(from, mmFilter, label, class)
+	case "tap":
+		return g.step(from, mmTap, label, class)
+
+	case "loop":
+		ends := g.step(from, mmJunction, "↻ "+label, class)
+
+		for _, name := range chronological(branches) {
+			for i := range ends {
+				ends[i].label = name
+			}
+
+			ends = g.traceBranch(ends, branches[name])
+		}
+
+		return ends
+
+	case "pipeline":
+		g.ids++
+		g.line(fmt.Sprintf("subgraph s%d [\"%s\"]", g.ids, mermaidText(label)))
+
+		sp := n.pipe.(*SubPipeline).pipeline()
+		ends := g.step(from, mmTerminal, mermaidName(named(sp.SourceName, sp.Source), "source"), "")
+
+		for _, b := range branches {
+			ends = g.traceBranch(ends, b)
+		}
+
+		ends = g.step(ends, mmTerminal, mermaidName(named(sp.SinkName, sp.Sink), "sink"), "")
+		g.line("end")
+
+		return ends
+	}
+
+	shape := mmFork
+	if kind == "if" || kind == "switch" {
+		shape = mmDecision
+	}
+
+	fork := g.step(from, shape, label, class)[0].id
+
+	names := make([]string, 0, len(branches))
+	for name := range branches {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var ends []openEdge
+	for _, name := range names {
+		ends = append(ends, g.traceBranch([]openEdge{{id: fork, label: caseName(name)}}, branches[name])...)
+	}
+
+	switch {
+	case len(ends) == 0:
+		return []openEdge{{id: fork}}
+	case kind == "if" || kind == "switch":
+		return ends
+	default:
+		return g.step(ends, mmJunction, " ", "")
+	}
+}
+
+func tracedTiming(n *tracerNode) string {
+	label := "canceled"
+	if !(n.endTime == n.startTime || (n.startTime == time.Time{} || n.endTime == time.Time{})) {
+		label = fmt.Sprintf("%.4fms", elapsedTime(n.startTime, n.endTime))
+	}
+
+	if n.timeout > 0 {
+		label += fmt.Sprintf(" ⏱ %v", n.timeout.Round(time.Microsecond))
+	}
+
+	if n.exited {
+		label += " ⏏ early exit"
+	}
+
+	return label
+}
+
+func tracedClass(n *tracerNode) string {
+	switch {
+	case n.error != nil:
+		return "failed"
+	case n.cancelled:
+		return "canceled"
+	case n.exited:
+		return "exited"
+	default:
+		return ""
+	}
+}
+
+func withVolume(label string, volume flowsPercent) string {
+	if volume.total <= 0 {
+		return label
+	}
+
+	label += fmt.Sprintf(" · %.2f%%", volume.total)
+
+	tags := make([]string, 0, len(volume.tagged))
+	for k := range volume.tagged {
+		tags = append(tags, k)
+	}
+
+	sort.Strings(tags)
+
+	for _, k := range tags {
+		label += fmt.Sprintf("<br/>%s: %.2f%%", k, volume.tagged[k])
+	}
+
+	return label
+}
+
+func mermaidName(r interface{}, fallback string) string {
+	if name := stageName(r); name != "" {
+		return name
+	}
+
+	return fallback
+}
+
+// mermaidText escapes what would otherwise close a quoted label.
+func mermaidText(s string) string {
+	return strings.NewReplacer("\"", "#quot;", "\n", "<br/>").Replace(strings.TrimSpace(s))
+}
@@ -0,0 +1,249 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	meterKey ctxKey = "pipeline.meter"
+
+	outcomeOK       = "ok"
+	outcomeError    = "error"
+	outcomeCanceled = "canceled"
+)
+
+var (
+	registry sync.Map
+
+	// durationBuckets spans from a millisecond to half a minute: the SDK defaults are meant for milliseconds.
+	durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+	fanOutBuckets   = []float64{1, 2, 4, 8, 16, 32, 64, 128, 256}
+)
+
+type (
+	instruments struct {
+		duration      metric.Float64Histogram
+		inFlight      metric.Int64UpDownCounter
+		errors        metric.Int64Counter
+		cancellations metric.Int64Counter
+		fanOut        metric.Int64Histogram
+	}
+
+	meters struct {
+		*instruments
+		pipeline string
+	}
+
+	meteredTask struct {
+		stopwatch
+		meters *meters
+		attrs  attribute.Set
+
+		mtx     sync.Mutex
+		ctx     context.Context
+		started time.Time
+		outcome string
+	}
+)
+
+func instrumentsOf(meter metric.Meter) (*instruments, error) {
+	if i, ok := registry.Load(meter); ok {
+		return i.(*instruments), nil
+	}
+
+	var (
+		i   instruments
+		err error
+	)
+
+	if i.duration, err = meter.Float64Histogram(
+		"pipeline_stage_duration_seconds",
+		metric.WithDescription("Time spent inside a pipeline stage"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	); err != nil {
+		return nil, err
+	}
+
+	if i.inFlight, err = meter.Int64UpDownCounter(
+		"pipeline_stage_inflight",
+		metric.WithDescription("Stage executions currently running"),
+	); err != nil {
+		return nil, err
+	}
+
+	if i.errors, err = meter.Int64Counter(
+		"pipeline_stage_errors",
+		metric.WithDescription("Stage executions that failed"),
+	); err != nil {
+		return nil, err
+	}
+
+	if i.cancellations, err = meter.Int64Counter(
+		"pipeline_stage_cancellations",
+		metric.WithDescription("Stage executions that were canceled"),
+	); err != nil {
+		return nil, err
+	}
+
+	if i.fanOut, err = meter.Int64Histogram(
+		"pipeline_fanout_size",
+		metric.WithDescription("Branches opened by a fan-out stage"),
+		metric.WithUnit("{branch}"),
+		metric.WithExplicitBucketBoundaries(fanOutBuckets...),
+	); err != nil {
+		return nil, err
+	}
+
+	actual, _ := registry.LoadOrStore(meter, &i)
+
+	return actual.(*instruments), nil
+}
+
+func withMeter(ctx context.Context, bp *Pipeline) context.Context {
+	if bp.Meter == nil {
+		if parent, ok := ctx.Value(meterKey).(*meters); ok {
+			return context.WithValue(ctx, meterKey, &meters{instruments: parent.instruments, pipeline: bp.Name})
+		}
+
+		return ctx
+	}
+
+	i, err := instrumentsOf(bp.Meter)
+	if err != nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, meterKey, &meters{instruments: i, pipeline: bp.Name})
+}
+
+func metered(ctx context.Context, pipe Traceable, task stopwatch) stopwatch {
+	m, ok := ctx.Value(meterKey).(*meters)
+	if !ok {
+		return task
+	}
+
+	return &meteredTask{stopwatch: task, meters: m, attrs: m.attributes(pipe), ctx: ctx}
+}
+
+func (m *meters) attributes(pipe Traceable) attribute.Set {
+	stage, kind := describe(pipe)
+
+	return attribute.NewSet(
+		attribute.String("pipeline", m.pipeline),
+		attribute.String("stage", stage),
+		attribute.String("kind", kind),
+	)
+}
+
+func (t *meteredTask) start(ctx context.Context) context.Context {
+	ctx = t.stopwatch.start(ctx)
+
+	t.mtx.Lock()
+	t.ctx, t.started, t.outcome = ctx, now(), outcomeOK
+	t.mtx.Unlock()
+
+	t.meters.inFlight.Add(ctx, 1, metric.WithAttributeSet(t.attrs))
+
+	return ctx
+}
+
+func (t *meteredTask) done() {
+	t.stopwatch.done()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	if t.started.IsZero() {
+		return
+	}
+
+	t.meters.inFlight.Add(t.ctx, -1, metric.WithAttributeSet(t.attrs))
+	t.meters.duration.Record(
+		t.ctx,
+		time.Since(t.started).Seconds(),
+		metric.WithAttributeSet(t.attrs),
+		metric.WithAttributes(attribute.String("outcome", t.outcome)),
+	)
+
+	t.started = time.Time{}
+}
+
+func (t *meteredTask) canceled() {
+	t.stopwatch.canceled()
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.outcome = outcomeCanceled
+	t.meters.cancellations.Add(t.ctx, 1, metric.WithAttributeSet(t.attrs))
+}
+
+func (t *meteredTask) fail(err error) {
+	t.stopwatch.fail(err)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	t.outcome = outcomeError
+	t.meters.errors.Add(t.ctx, 1, metric.WithAttributeSet(t.attrs))
+}
+
+func recordFanOut(ctx context.Context, pipe Traceable, size int) {
+	m, ok := ctx.Value(meterKey).(*meters)
+	if !ok {
+		return
+	}
+
+	m.fanOut.Record(ctx, int64(size), metric.WithAttributeSet(m.attributes(pipe)))
+}
+
+func RegisterTrafficMetrics(meter metric.Meter, p *Pipeline) (metric.Registration, error) {
+	volume, err := meter.Int64ObservableCounter(
+		"pipeline_branch_traffic",
+		metric.WithDescription("Items routed to each branch since process start"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged, err := meter.Int64ObservableCounter(
+		"pipeline_branch_traffic_tagged",
+		metric.WithDescription("Items routed to each branch since process start, by traffic tag"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		walk(p.Flow, func(pipe Pipe) {
+			c, ok := pipe.(counted)
+			if !ok {
+				return
+			}
+
+			stage, _ := describe(pipe)
+
+			c.flows().totals(func(branch string, counter flowCounter) {
+				attrs := []attribute.KeyValue{
+					attribute.String("pipeline", p.Name),
+					attribute.String("stage", stage),
+					attribute.String("branch", branch),
+				}
+
+				o.ObserveInt64(volume, int64(counter.total), metric.WithAttributes(attrs...))
+
+				for tag, count := range counter.tagged {
+					o.ObserveInt64(tagged, int64(count), metric.WithAttributes(append(attrs, attribute.String("tag", tag))...))
+				}
+			})
+		})
+
+		return nil
+	}, volume, tagged)
+}
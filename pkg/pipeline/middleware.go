@@ -0,0 +1,155 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	middlewareKey ctxKey = "pipeline.middlewares"
+	branchPathKey ctxKey = "pipeline.branch"
+	branchNameKey ctxKey = "pipeline.branch.name"
+
+	KindResolver    StageKind = "resolver"
+	KindSplitter    StageKind = "splitter"
+	KindJoiner      StageKind = "joiner"
+	KindMerger      StageKind = "merger"
+	KindDecider     StageKind = "decider"
+	KindSelector    StageKind = "selector"
+	KindPartitioner StageKind = "partitioner"
+	KindCondition   StageKind = "condition"
+	KindPredicate   StageKind = "predicate"
+	KindEffect      StageKind = "effect"
+)
+
+type (
+	StageKind string
+
+	StageInfo struct {
+		Pipeline string
+		Stage    string
+		Kind     StageKind
+		Name     string
+		Branch   string
+	}
+
+	Middleware func(next StageFn, info StageInfo) StageFn
+
+	interceptors struct {
+		pipeline string
+		chain    []Middleware
+	}
+)
+
+func BranchPath(ctx context.Context) string {
+	path, _ := ctx.Value(branchPathKey).(string)
+
+	return path
+}
+
+func branchName(ctx context.Context) string {
+	name, _ := ctx.Value(branchNameKey).(string)
+
+	return name
+}
+
+func withBranchPath(ctx context.Context, name string) context.Context {
+	path := name
+	if parent := BranchPath(ctx); parent != "" {
+		path = parent + "/" + name
+	}
+
+	return context.WithValue(context.WithValue(ctx, branchPathKey, path), branchNameKey, name)
+}
+
+func withMiddlewares(ctx context.Context, bp *Pipeline) context.Context {
+	var chain []Middleware
+
+	if parent, ok := ctx.Value(middlewareKey).(*interceptors); ok {
+		chain = append(chain, parent.chain...)
+	}
+
+	chain = append(chain, bp.Middlewares...)
+
+	return context.WithValue(ctx, middlewareKey, &interceptors{pipeline: bp.Name, chain: chain})
+}
+
+func intercept(
+	ctx context.Context,
+	kind StageKind,
+	stage string,
+	fn interface{},
+	local []Middleware,
+	next StageFn,
+) StageFn {
+	var (
+		chain []Middleware
+		info  = StageInfo{Stage: stage, Kind: kind, Name: stageName(fn), Branch: BranchPath(ctx)}
+	)
+
+	if i, ok := ctx.Value(middlewareKey).(*interceptors); ok {
+		info.Pipeline = i.pipeline
+		chain = i.chain
+	}
+
+	for idx := len(local) - 1; idx >= 0; idx-- {
+		next = local[idx](next, info)
+	}
+
+	for idx := len(chain) - 1; idx >= 0; idx-- {
+		next = chain[idx](next, info)
+	}
+
+	return next
+}
+
+func invoke[T any](
+	ctx context.Context,
+	kind StageKind,
+	stage string,
+	fn interface{},
+	local []Middleware,
+	data interface{},
+	call func(context.Context, interface{}) (T, error),
+) (
+	T,
+	error,
+) {
+	var zero T
+
+	// withMiddlewares installs interceptors on every run to carry the pipeline name, so an empty chain
+	// is what tells there is nothing to intercept.
+	if i, ok := ctx.Value(middlewareKey).(*interceptors); (!ok || len(i.chain) == 0) && len(local) == 0 {
+		return call(ctx, data)
+	}
+
+	out, err := intercept(ctx, kind, stage, fn, local, func(ctx context.Context, data interface{}) (interface{}, error) {
+		return call(ctx, data)
+	})(ctx, data)
+	if err != nil || out == nil {
+		return zero, err
+	}
+
+	typed, ok := out.(T)
+	if !ok {
+		return zero, fmt.Errorf("middleware on %s %s returned %T", kind, stage, out)
+	}
+
+	return typed, nil
+}
+
+func joinWith(data interface{}, joiner JoinerFn) func(context.Context, interface{}) (interface{}, error) {
+	return func(ctx context.Context, joined interface{}) (interface{}, error) {
+		all, _ := joined.([]interface{})
+
+		return joiner(ctx, data, all)
+	}
+}
+
+func mergeWith(merger FanInFn) func(context.Context, interface{}) (interface{}, error) {
+	return func(ctx context.Context, joined interface{}) (interface{}, error) {
+		all, _ := joined.([]interface{})
+
+		return merger(ctx, all)
+	}
+}
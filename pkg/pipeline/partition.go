@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"sync"
+	"time"
 )
 
 type (
@@ -24,9 +24,12 @@ type (
 
 		Tagger        PartitionTagger
 		TrafficTagger TrafficTagger
+		TrafficWindow time.Duration
+		Timeout       time.Duration
+		ErrorPolicy   ErrorPolicy
+		Middlewares   []Middleware
 
-		mtx      sync.Mutex
-		counters map[string]*flowCounter
+		traffic traffic
 	}
 )
 
@@ -44,15 +47,9 @@ func (pp *PartitionPipe) connect(
 
 	panicProof(
 		func() {
-			select {
-			case data, ok := <-in:
-				if ok {
-					pp.handleInput(ctx, out, errors, tracer, b, data)
-				}
-			case <-ctx.Done():
-				tracer.canceled()
-				return
-			}
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				pp.handleInput(ctx, out, errors, tracer, b, data)
+			})
 		},
 		notifyPanicAsError(ctx, errors, b, tracer),
 		closeOutput(out, errors),
@@ -71,34 +68,56 @@ func (pp *PartitionPipe) handleInput(
 ) {
 	defer tracer.done()
 
-	tracer.start(ctx)
+	ctx = tracer.start(ctx)
 
-	paths, err := pp.Partitioner(ctx, data)
+	timer := newStageTimer(ctx, pp, pp.Name, pp.Timeout, tracer)
+	defer timer.stop()
+
+	paths, err := bounded(timer, func() ([]PartitionData, error) {
+		return invoke(timer, KindPartitioner, pp.Name, pp.Partitioner, pp.Middlewares, data, pp.Partitioner)
+	})
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
 	}
 
-	pp.count(ctx, paths)
+	for _, path := range paths {
+		pp.traffic.count(ctx, path.Name, pp.TrafficTagger, path.Data, pp.TrafficWindow)
+	}
+
+	sb := b.scope()
 
-	c, joined, err := pp.runFlows(ctx, paths, b)
+	var c int
+
+	joined, err := bounded(timer, func() (all []interface{}, err error) {
+		c, all, err = pp.runFlows(timer, paths, sb)
+		return all, err
+	})
 	if err != nil {
-		cancel(tracer, err, errors, b)
+		timer.abort(tracer, err, errors, b)
 		return
 	}
 
-	if isCancelled(c, len(joined)) {
+	if pp.ErrorPolicy.incomplete(c, len(joined)) {
 		tracer.canceled()
 		return
 	}
 
-	merged, err := pp.Merger(ctx, joined)
+	joined, err = pp.ErrorPolicy.collect(joined)
 	if err != nil {
 		fail(tracer, err, errors, b)
 		return
 	}
 
-	out <- merged
+	merged, err := bounded(timer, func() (interface{}, error) {
+		return invoke(timer, KindMerger, pp.Name, pp.Merger, pp.Middlewares, joined, mergeWith(pp.Merger))
+	})
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	emit(ctx, out, merged)
 }
 
 func (pp *PartitionPipe) runFlows(
@@ -111,35 +130,36 @@ func (pp *PartitionPipe) runFlows(
 	error,
 ) {
 	var (
-		pathOuts []<-chan interface{}
-		pathErrs []<-chan error
+		branches []branch
 		flowCtx  context.Context
-		counter  int
 	)
 
 	for idx, dataPath := range paths {
 		if stream, ok := pp.Paths[dataPath.Name]; ok {
-			counter++
-
-			pathIn := make(chan interface{}, 1)
-
 			flowCtx = CtxBranch(ctx, fmt.Sprintf("%s#%v", dataPath.Name, idx))
 			flowCtx = openBranch(flowCtx, pp, pp.Tagger(flowCtx, dataPath))
-			pathOut, ferr := connectFlow(flowCtx, pathIn, stream, b)
-
-			pathOuts = append(pathOuts, pathOut)
-			pathErrs = append(pathErrs, ferr...)
 
-			go feed(flowCtx, pathIn, dataPath.Data)
+			branches = append(branches, branch{ctx: flowCtx, flow: stream, data: dataPath.Data})
 		}
 	}
 
-	all, err := mergeAll(
-		func() <-chan interface{} { return fanIn(ctx, pathOuts...) },
-		pathErrs,
-	)
+	recordFanOut(ctx, pp, len(branches))
+
+	all, err := pp.ErrorPolicy.run(ctx, branches, b, 0)
+
+	return len(branches), all, err
+}
 
-	return counter, all, err
+func (pp *PartitionPipe) Stats() TrafficStats {
+	return pp.traffic.stats(pp.TrafficWindow)
+}
+
+func (pp *PartitionPipe) ResetStats() {
+	pp.traffic.reset()
+}
+
+func (pp *PartitionPipe) flows() *traffic {
+	return &pp.traffic
 }
 
 func (pp *PartitionPipe) draw(s Skin) string {
@@ -152,7 +172,7 @@ func (pp *PartitionPipe) draw(s Skin) string {
 
 	sort.Strings(sortedKeys)
 
-	volume := pp.flowVolume()
+	volume := pp.traffic.flowVolume(pp.TrafficWindow)
 
 	output += "split \n"
 
@@ -175,6 +195,10 @@ func (pp *PartitionPipe) draw(s Skin) string {
 
 	output += "endsplit \n"
 
+	if policy := policyNote(pp.ErrorPolicy); policy != "" {
+		output += fmt.Sprintf("note right\n%s\nend note\n", policy)
+	}
+
 	return output
 }
 
@@ -211,65 +235,3 @@ func (pp *PartitionPipe) traced(n *tracerNode) string {
 
 	return output
 }
-
-func (pp *PartitionPipe) count(ctx context.Context, paths []PartitionData) {
-	defer pp.mtx.Unlock()
-	pp.mtx.Lock()
-
-	if pp.counters == nil {
-		pp.counters = make(map[string]*flowCounter)
-	}
-
-	for _, path := range paths {
-		var (
-			counter *flowCounter
-			ok      bool
-		)
-
-		if counter, ok = pp.counters[path.Name]; !ok {
-			counter = &flowCounter{tagged: make(map[string]uint64)}
-		}
-
-		if pp.TrafficTagger != nil {
-			tag := pp.TrafficTagger(ctx, path.Data)
-
-			counter.tagged[tag]++
-		}
-
-		counter.total++
-		pp.counters[path.Name] = counter
-	}
-}
-
-func (pp *PartitionPipe) flowVolume() map[string]flowsPercent {
-	defer pp.mtx.Unlock()
-	pp.mtx.Lock()
-
-	out := make(map[string]flowsPercent)
-
-	if pp.counters == nil {
-		return out
-	}
-
-	var global uint64
-	for _, v := range pp.counters {
-		global += v.total
-	}
-
-	for k, v := range pp.counters {
-		branchCounter := v.total
-
-		percentMap := flowsPercent{
-			total:  (float32(branchCounter) / float32(global)) * oneHundred,
-			tagged: make(map[string]float32),
-		}
-
-		for kk, vv := range v.tagged {
-			percentMap.tagged[kk] = (float32(vv) / float32(branchCounter)) * oneHundred
-		}
-
-		out[k] = percentMap
-	}
-
-	return out
-}
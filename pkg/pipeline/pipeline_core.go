@@ -2,6 +2,11 @@ package pipeline
 
 import (
 	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type (
@@ -21,13 +26,22 @@ type (
 		Description    string
 		SourceComments string
 		SinkComments   string
+		SourceName     string
+		SinkName       string
 
 		Source SourceFn
 		Flow   Flow
 		Sink   SinkFn
+		Budget time.Duration
+
+		Middlewares []Middleware
+		SpanTracer  trace.Tracer
+		Meter       metric.Meter
 
 		BlueprintSkin Skin
 		TraceSkin     Skin
+
+		latency latency
 	}
 
 	FanOutFn func(context.Context, interface{}) ([]interface{}, error)
@@ -59,6 +73,15 @@ type (
 		total  float32
 		tagged map[string]float32
 	}
+
+	// Result is the outcome of a single input or branch. RunStream sends exactly one per input, in input
+	// order, with Seq set to the input's position; an error that aborts the whole stream comes with Seq -1.
+	// Under CollectAll, mergers and joiners get one per branch, with Seq set to the branch index.
+	Result struct {
+		Value interface{}
+		Err   error
+		Seq   int
+	}
 )
 
 const oneHundred = 100
@@ -76,22 +99,49 @@ func Run(ctx context.Context, input interface{}, bp *Pipeline, traced bool) (int
 	}
 
 	pCtx, breaker := newBreaker(pCtx)
+	pCtx = instrumented(withExit(pCtx, breaker), bp)
 
 	ch, err := source(pCtx, input, bp.Source)
 	if err != nil {
 		return nil, err
 	}
 
-	pCh, eCh := connectFlow(pCtx, ch, bp.Flow, breaker)
+	pCh, eCh := connectFlow(withBudget(pCtx, bp), ch, bp.Flow, breaker)
 
 	return sink(pCtx, pCh, eCh, bp.Sink, breaker)
 }
 
-func RunWithTracer(ctx context.Context, input interface{}, bp *Pipeline) (interface{}, string, error) {
+func RunStream(ctx context.Context, in <-chan interface{}, bp *Pipeline) <-chan Result {
+	pCtx, breaker := newBreaker(ctx)
+	breaker.stream = true
+	pCtx = instrumented(withExit(pCtx, breaker), bp)
+
+	ch := streamSource(pCtx, in, bp.Source, breaker)
+	pCh, eCh := connectFlow(pCtx, ch, bp.Flow, breaker)
+
+	return streamSink(ctx, pCtx, pCh, eCh, bp.Sink, breaker)
+}
+
+func RunWithTracer(ctx context.Context, input interface{}, bp *Pipeline) (interface{}, *Trace, error) {
 	tCtx := newTracer(ctx, bp)
 	out, err := Run(tCtx, input, bp, false /* <- tCtx is traced */)
 
-	return out, TracedLink(tCtx), err
+	trace := traceOf(tCtx)
+	trace.Analysis = trace.Analyze()
+
+	// A diagram link problem is reported on the trace, never as the run's error.
+	link, linkErr := TracedLink(tCtx)
+	if linkErr != nil {
+		trace.LinkError = linkErr.Error()
+	}
+
+	trace.Link = link
+
+	return out, trace, err
+}
+
+func instrumented(ctx context.Context, bp *Pipeline) context.Context {
+	return withLatency(withMeter(withSpans(withMiddlewares(ctx, bp), bp), bp), bp)
 }
 
 func source(
@@ -109,6 +159,11 @@ func source(
 
 	ch := make(chan interface{}, 1)
 
+	if ctx.Err() != nil {
+		close(ch)
+		return ch, nil
+	}
+
 	go func() {
 		defer close(ch)
 
@@ -122,6 +177,46 @@ func source(
 	return ch, nil
 }
 
+func streamSource(
+	ctx context.Context,
+	in <-chan interface{},
+	resolver SourceFn,
+	b breaker,
+) chan interface{} {
+	ch := make(chan interface{}, 1)
+
+	go func() {
+		defer close(ch)
+
+		for {
+			select {
+			case req, ok := <-in:
+				if !ok {
+					return
+				}
+
+				iCtx, ib := b.scopeItem(ctx)
+
+				token, err := resolver(iCtx, req)
+				if ib.settle(ctx, ch) {
+					continue
+				}
+
+				if err != nil {
+					token = settled{err: err}
+				}
+
+				emit(ctx, ch, token)
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
 func connectFlow(
 	ctx context.Context,
 	source <-chan interface{},
@@ -131,15 +226,15 @@ func connectFlow(
 	<-chan interface{},
 	[]<-chan error,
 ) {
-	var errcList = make([]<-chan error, len(flow))
+	var errcList = make([]<-chan error, 0, len(flow))
 
 	in := source
 
-	for idx, stage := range flow {
+	for _, stage := range fuse(flow) {
 		next, errc := stage.connect(ctx, in, b)
 		in = next
 
-		errcList[idx] = errc
+		errcList = append(errcList, errc)
 	}
 
 	return in, errcList
@@ -156,6 +251,11 @@ func sink(
 	error,
 ) {
 	err := WaitForPipeline(errc...)
+
+	if out, exited := b.exited(); exited {
+		return resolver(ctx, out)
+	}
+
 	if err != nil {
 		return nil, err
 	}
@@ -179,6 +279,68 @@ func sink(
 	return resolver(ctx, out)
 }
 
+func streamSink(
+	ctx context.Context,
+	pCtx context.Context,
+	in <-chan interface{},
+	errc []<-chan error,
+	resolver SinkFn,
+	b breaker,
+) <-chan Result {
+	results := make(chan Result)
+
+	go func() {
+		defer close(results)
+		defer b.cancel()
+
+		var (
+			errs = mergeErrors(errc...)
+			seq  int
+		)
+
+		for in != nil || errs != nil {
+			var res Result
+
+			select {
+			case data, ok := <-in:
+				if !ok {
+					in = nil
+					continue
+				}
+
+				res.Seq = seq
+				seq++
+
+				s, ok := data.(settled)
+				switch {
+				case !ok:
+					res.Value, res.Err = resolver(pCtx, data)
+				case s.err != nil:
+					res.Err = s.err
+				default:
+					res.Value, res.Err = resolver(pCtx, s.value)
+				}
+
+			case err, ok := <-errs:
+				if !ok {
+					errs = nil
+					continue
+				}
+
+				res.Err, res.Seq = err, -1
+			}
+
+			select {
+			case results <- res:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
 func fail(tracer stopwatch, err error, errors chan error, b breaker) {
 	tracer.fail(err)
 	sendError(err, errors, b)
@@ -190,16 +352,25 @@ func cancel(tracer stopwatch, err error, errors chan error, b breaker) {
 }
 
 func sendError(err error, errors chan error, b breaker) {
+	if b.item {
+		b.earlyExit(settled{err: err})
+		b.cancel()
+
+		return
+	}
+
 	errors <- err
 
-	b.cancel()
+	if !b.stream {
+		b.cancel()
+	}
 }
 
 func done(ctx context.Context, out chan interface{}, in <-chan interface{}, cancel stopwatch) {
 	select {
 	case v, ok := <-in:
 		if ok {
-			out <- v
+			emit(ctx, out, v)
 			return
 		}
 
@@ -208,3 +379,90 @@ func done(ctx context.Context, out chan interface{}, in <-chan interface{}, canc
 		return
 	}
 }
+
+func consume(
+	ctx context.Context,
+	in <-chan interface{},
+	out chan interface{},
+	tracer stopwatch,
+	b breaker,
+	handle func(context.Context, breaker, interface{}),
+) {
+	var consumed bool
+
+	for {
+		select {
+		case data, ok := <-in:
+			if !ok {
+				if !consumed {
+					tracer.canceled()
+				}
+
+				return
+			}
+
+			if s, ok := data.(settled); ok {
+				emit(ctx, out, s)
+				continue
+			}
+
+			consumed = true
+
+			if !b.stream {
+				handle(ctx, b, data)
+			} else {
+				iCtx, ib := b.scopeItem(ctx)
+				handleItem(iCtx, ib, tracer, handle, data)
+				ib.settle(ctx, out)
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+		case <-ctx.Done():
+			if !consumed {
+				tracer.canceled()
+			}
+
+			return
+		}
+	}
+}
+
+// handleItem recovers a panic while handling a stream item and settles the item with it, so the stage
+// keeps serving the rest of the stream.
+func handleItem(
+	ctx context.Context,
+	b breaker,
+	tracer stopwatch,
+	handle func(context.Context, breaker, interface{}),
+	data interface{},
+) {
+	defer func() {
+		if p := recover(); p != nil {
+			err := fmt.Errorf("panic recovered: %+v", p)
+			tracer.fail(err)
+			b.earlyExit(settled{err: err})
+		}
+	}()
+
+	handle(ctx, b, data)
+}
+
+func emit(ctx context.Context, out chan interface{}, value interface{}) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	select {
+	case out <- value:
+		return
+	default:
+	}
+
+	select {
+	case out <- value:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunStreamRecoversPanicPerItem(t *testing.T) {
+	const (
+		items    = 20
+		panicsOn = 2
+	)
+
+	bp := &Pipeline{
+		Name:   "stream",
+		Source: passThrough,
+		Sink:   passThrough,
+		Flow: Flow{
+			Stage(func(_ context.Context, data interface{}) (interface{}, error) {
+				if data.(int) == panicsOn {
+					panic("boom")
+				}
+
+				return data, nil
+			}),
+			Stage(passThrough),
+		},
+	}
+
+	in := make(chan interface{})
+	go func() {
+		defer close(in)
+
+		for i := 0; i < items; i++ {
+			in <- i
+		}
+	}()
+
+	var results []Result
+	for r := range RunStream(context.Background(), in, bp) {
+		results = append(results, r)
+	}
+
+	if len(results) != items {
+		t.Fatalf("expected %d results, got %d: %+v", items, len(results), results)
+	}
+
+	for i, r := range results {
+		if r.Seq != i {
+			t.Errorf("result %d has Seq %d", i, r.Seq)
+		}
+
+		switch {
+		case i == panicsOn && r.Err == nil:
+			t.Errorf("expected the panic on item %d to be reported", i)
+		case i != panicsOn && (r.Err != nil || r.Value != i):
+			t.Errorf("item %d: got %+v", i, r)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package pipeline
 
 import (
-	"encoding/hex"
 	"fmt"
 	"reflect"
 	"runtime"
@@ -9,9 +8,11 @@ import (
 )
 
 const (
-	mapper       = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
-	RenderServer = "http://plantuml.com/plantuml/svg/~1"
-	secret       = "6368616e676520746869732070617373"
+	mapper = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz-_"
+	// encryptedPrefix stays outside the PlantUML encoding, whose markers start with "~", so a standard
+	// server never takes an encrypted link for one of its own.
+	encryptedPrefix = "!1"
+	keySeparator    = "."
 
 	methodFunction = 3
 	function       = 2
@@ -21,7 +22,8 @@ const (
 	hThree      = 0x3
 )
 
-var key, _ = hex.DecodeString(secret)
+// RenderServer is the base URL diagram links are built on; point it at a self-hosted PlantUML server to keep diagrams in-house.
+var RenderServer = "http://plantuml.com/plantuml/svg/"
 
 func (p *Pipeline) Diagram() string {
 	output := "@startuml\nstart\n"
@@ -55,7 +57,7 @@ func (p *Pipeline) skin() string {
 func (p *Pipeline) source() string {
 	var output string
 
-	output += drawStage(p.Source)
+	output += drawStage(named(p.SourceName, p.Source))
 	output += "note left\n <font size=\"16\">//source ⟶ //</font>  \n end note\n"
 
 	if p.SourceComments != "" {
@@ -79,7 +81,7 @@ func (p *Pipeline) sink() string {
 	var output string
 
 	output += "(★) \n"
-	output += drawStage(p.Sink)
+	output += drawStage(named(p.SinkName, p.Sink))
 	output += "note right\n <font size=\"16\">// ⟵ sink//</font> \n  end note\n"
 
 	if p.SinkComments != "" {
@@ -142,15 +144,32 @@ func formattedResolver(r interface{}) string {
 func resolverName(r interface{}) (string, []string) {
 	fullName := funcName(r)
 	slash := strings.Split(fullName, "/")
+
+	if len(slash) <= FunctionsNamePrefixPrune {
+		return fullName, []string{fullName}
+	}
+
 	split := strings.Split(strings.Join(slash[FunctionsNamePrefixPrune:], "/"), ".")
 
 	return fullName, split
 }
 
 func funcName(r interface{}) string {
+	if name, ok := r.(string); ok {
+		return name
+	}
+
 	return runtime.FuncForPC(reflect.ValueOf(r).Pointer()).Name()
 }
 
+func named(name string, r interface{}) interface{} {
+	if name != "" {
+		return name
+	}
+
+	return r
+}
+
 func fontMultiline(raw string, fontTag string) string {
 	var out string
 
@@ -104,7 +104,14 @@ func notifyPanicAsError(
 ) func(panic interface{}) {
 	return func(panic interface{}) {
 		err := fmt.Errorf("panic recovered: %+v", panic)
-		fail(tracer, err, errors, b)
+		tracer.fail(err)
+
+		select {
+		case errors <- err:
+		case <-ctx.Done():
+		}
+
+		b.cancel()
 	}
 }
 
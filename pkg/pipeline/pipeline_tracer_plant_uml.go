@@ -6,8 +6,13 @@ import (
 	"time"
 )
 
+const (
+	earlyExit    = "<color:darkOrange>**⏏ early exit**</color>"
+	criticalPath = "#crimson,bold"
+)
+
 func (t *tracer) TracedDiagram(txnID string) string {
-	t.end = now()
+	t.finish()
 
 	output := "@startuml \nstart\n"
 	output += string(t.skin)
@@ -18,7 +23,12 @@ func (t *tracer) TracedDiagram(txnID string) string {
 	output += notes(t.sourceNotes)
 
 	hasNodes := len(t.nodes) > 0
-	if hasNodes {
+	switch {
+	case t.sourceExited:
+		output += fmt.Sprintf("-[dotted]-> %s; \n", earlyExit)
+		output += traceBranch(t.nodes)
+
+	case hasNodes:
 		output += executionArrow(t.start, t.nodes[0].startTime)
 		output += traceBranch(t.nodes)
 	}
@@ -49,14 +59,35 @@ func executionArrow(start, end time.Time) string {
 	return fmt.Sprintf("-[dotted]-> //%.4fms//; \n", elapsedTime(start, end))
 }
 
-func TracedLink(ctx context.Context) string {
+func nodeArrow(n *tracerNode) string {
+	label := "//canceled//"
+	if !(n.endTime == n.startTime || (n.startTime == time.Time{} || n.endTime == time.Time{})) {
+		label = fmt.Sprintf("//%.4fms//", elapsedTime(n.startTime, n.endTime))
+	}
+
+	if n.timeout > 0 {
+		label += fmt.Sprintf(" ⏱ %v", n.timeout.Round(time.Microsecond))
+	}
+
+	if n.exited {
+		label += " " + earlyExit
+	}
+
+	if n.critical {
+		return fmt.Sprintf("-[%s]-> %s; \n", criticalPath, label)
+	}
+
+	return fmt.Sprintf("-[dotted]-> %s; \n", label)
+}
+
+func TracedLink(ctx context.Context) (string, error) {
 	if disabled(ctx) {
-		return ""
+		return "", nil
 	}
 
-	diagram := ctx.Value(tracerKey).(*tracer).TracedDiagram(txnID(ctx))
+	diagram := ctx.Value(tracerKey).(*tracer).TracedDiagram(TxnID(ctx))
 
-	return fmt.Sprint(RenderServer, Encoded(diagram))
+	return link(diagram)
 }
 
 func traceBranch(b []*tracerNode) string {
@@ -66,7 +97,7 @@ func traceBranch(b []*tracerNode) string {
 		node.mtx.Lock()
 
 		out += node.pipe.traced(node)
-		out += executionArrow(node.startTime, node.endTime)
+		out += nodeArrow(node)
 
 		node.mtx.Unlock()
 	}
@@ -82,10 +113,6 @@ func elapsedTime(start, end time.Time) float64 {
 	return float64(end.Sub(start)) / 1000000
 }
 
-func txnID(_ context.Context) string {
-	return ""
-}
-
 func notesOf(n *tracerNode) string {
 	out := ""
 
@@ -107,6 +134,28 @@ func notesOf(n *tracerNode) string {
 	return out
 }
 
+func attemptsOf(n *tracerNode) string {
+	out := ""
+
+	if len(n.attempts) > 1 {
+		out += "note left \n"
+		out += "<b>↻ attempts</b>\n"
+
+		for _, a := range n.attempts {
+			outcome := "✔"
+			if a.error != nil {
+				outcome = fmt.Sprintf("☠ %s", a.error.Error())
+			}
+
+			out += fmt.Sprintf("# //%.4fms// %s\n", elapsedTime(a.startTime, a.endTime), outcome)
+		}
+
+		out += "end note \n"
+	}
+
+	return out
+}
+
 func notes(notes []string) string {
 	out := ""
 
@@ -0,0 +1,172 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	Quorum struct {
+		Name     string
+		Label    string
+		Comments string
+
+		Streams  []Flow
+		Required int
+		Merger   FanInFn
+		Timeout  time.Duration
+
+		Middlewares []Middleware
+	}
+)
+
+func (q *Quorum) connect(
+	ctx context.Context,
+	in <-chan interface{},
+	br breaker,
+) (
+	<-chan interface{},
+	<-chan error,
+) {
+	out := make(chan interface{}, 1)
+	errors := make(chan error, 1)
+	tracer := traceMe(ctx, q)
+
+	panicProof(
+		func() {
+			consume(ctx, in, out, tracer, br, func(ctx context.Context, br breaker, data interface{}) {
+				q.handleInput(ctx, out, errors, tracer, br, data)
+			})
+		},
+		notifyPanicAsError(ctx, errors, br, tracer),
+		closeOutput(out, errors),
+	)
+
+	return out, errors
+}
+
+func (q *Quorum) handleInput(
+	ctx context.Context,
+	out chan interface{},
+	errors chan error,
+	tracer stopwatch,
+	br breaker,
+	data interface{},
+) {
+	defer tracer.done()
+
+	ctx = tracer.start(ctx)
+
+	timer := newStageTimer(ctx, q, q.Name, q.Timeout, tracer)
+	defer timer.stop()
+
+	joined, err := bounded(timer, func() ([]interface{}, error) {
+		return contest(timer, q.branches(timer, data), q.required(), 0)
+	})
+	if err != nil {
+		timer.abort(tracer, err, errors, br)
+		return
+	}
+
+	merged, err := bounded(timer, func() (interface{}, error) {
+		return invoke(timer, KindMerger, q.Name, q.Merger, q.Middlewares, joined, mergeWith(q.Merger))
+	})
+	if err != nil {
+		fail(tracer, err, errors, br)
+		return
+	}
+
+	emit(ctx, out, merged)
+}
+
+func (q *Quorum) required() int {
+	if q.Required <= 0 {
+		return len(q.Streams)/2 + 1
+	}
+
+	return q.Required
+}
+
+func (q *Quorum) branches(ctx context.Context, data interface{}) []branch {
+	branches := make([]branch, len(q.Streams))
+
+	for idx, flow := range q.Streams {
+		branchName := fmt.Sprintf("%s#%v", q.Name, idx)
+		flowCtx := openBranch(CtxBranch(ctx, branchName), q, branchName)
+
+		branches[idx] = branch{ctx: flowCtx, flow: flow, data: data}
+	}
+
+	recordFanOut(ctx, q, len(branches))
+
+	return branches
+}
+
+func (q *Quorum) draw(s Skin) string {
+	output := "fork \n"
+
+	for i, flow := range q.Streams {
+		if i > 0 {
+			output += "fork again\n"
+		}
+
+		for _, pipe := range flow {
+			output += pipe.draw(s)
+		}
+	}
+
+	output += fmt.Sprintf("end fork {%d of %d}\n", q.required(), len(q.Streams))
+	output += q.comments()
+
+	return output
+}
+
+func (q *Quorum) comments() string {
+	var output string
+
+	output += "note right \n"
+	output += fontMultiline(q.Label, "<font size=\"24\">")
+	output += fmt.Sprintf("\n//quorum %d of %d//", q.required(), len(q.Streams))
+
+	if q.Comments != "" {
+		output += "\n"
+		output += q.Comments
+	}
+
+	output += "\nend note \n"
+
+	return output
+}
+
+func (q *Quorum) traced(n *tracerNode) string {
+	var (
+		output  string
+		counter int
+	)
+
+	if n.error != nil {
+		output += "fork \n"
+		output += fmt.Sprintf(": ☠ %+v; \n", n.error)
+		output += fmt.Sprintf("end fork {%d of %d}\n", q.required(), len(q.Streams))
+		output += notesOf(n)
+
+		return output
+	}
+
+	output += "fork \n"
+
+	for _, b := range n.branches.dump() {
+		if counter > 0 {
+			output += "fork again \n"
+		}
+
+		output += traceBranch(b)
+		counter++
+	}
+
+	output += fmt.Sprintf("end fork {%d of %d}\n", q.required(), len(q.Streams))
+	output += notesOf(n)
+
+	return output
+}
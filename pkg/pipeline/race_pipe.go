@@ -0,0 +1,157 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	Race struct {
+		Name     string
+		Label    string
+		Comments string
+
+		Streams []Flow
+		Hedge   time.Duration
+		Timeout time.Duration
+	}
+)
+
+func (r *Race) connect(
+	ctx context.Context,
+	in <-chan interface{},
+	br breaker,
+) (
+	<-chan interface{},
+	<-chan error,
+) {
+	out := make(chan interface{}, 1)
+	errors := make(chan error, 1)
+	tracer := traceMe(ctx, r)
+
+	panicProof(
+		func() {
+			consume(ctx, in, out, tracer, br, func(ctx context.Context, br breaker, data interface{}) {
+				r.handleInput(ctx, out, errors, tracer, br, data)
+			})
+		},
+		notifyPanicAsError(ctx, errors, br, tracer),
+		closeOutput(out, errors),
+	)
+
+	return out, errors
+}
+
+func (r *Race) handleInput(
+	ctx context.Context,
+	out chan interface{},
+	errors chan error,
+	tracer stopwatch,
+	br breaker,
+	data interface{},
+) {
+	defer tracer.done()
+
+	ctx = tracer.start(ctx)
+
+	timer := newStageTimer(ctx, r, r.Name, r.Timeout, tracer)
+	defer timer.stop()
+
+	winners, err := bounded(timer, func() ([]interface{}, error) {
+		return contest(timer, r.branches(timer, data), 1, r.Hedge)
+	})
+	if err != nil {
+		timer.abort(tracer, err, errors, br)
+		return
+	}
+
+	emit(ctx, out, winners[0])
+}
+
+func (r *Race) branches(ctx context.Context, data interface{}) []branch {
+	branches := make([]branch, len(r.Streams))
+
+	for idx, flow := range r.Streams {
+		branchName := fmt.Sprintf("%s#%v", r.Name, idx)
+		flowCtx := openBranch(CtxBranch(ctx, branchName), r, branchName)
+
+		branches[idx] = branch{ctx: flowCtx, flow: flow, data: data}
+	}
+
+	recordFanOut(ctx, r, len(branches))
+
+	return branches
+}
+
+func (r *Race) draw(s Skin) string {
+	output := "fork \n"
+
+	for i, flow := range r.Streams {
+		if i > 0 {
+			output += "fork again\n"
+		}
+
+		for _, pipe := range flow {
+			output += pipe.draw(s)
+		}
+	}
+
+	output += "end merge \n"
+	output += r.comments()
+
+	return output
+}
+
+func (r *Race) comments() string {
+	var output string
+
+	output += "note right \n"
+	output += fontMultiline(r.Label, "<font size=\"24\">")
+	output += "\n//first wins//"
+
+	if r.Hedge > 0 {
+		output += fmt.Sprintf("\n//hedge after %v//", r.Hedge)
+	}
+
+	if r.Comments != "" {
+		output += "\n"
+		output += r.Comments
+	}
+
+	output += "\nend note \n"
+
+	return output
+}
+
+func (*Race) traced(n *tracerNode) string {
+	var (
+		output  string
+		counter int
+	)
+
+	if n.error != nil {
+		output += "fork \n"
+		output += fmt.Sprintf(": ☠ %+v; \n", n.error)
+		output += "end merge \n"
+		output += notesOf(n)
+
+		return output
+	}
+
+	output += "fork \n"
+
+	for _, b := range n.branches.dump() {
+		if counter > 0 {
+			output += "fork again \n"
+		}
+
+		output += traceBranch(b)
+		counter++
+	}
+
+	output += "end merge \n"
+	output += notesOf(n)
+
+	return output
+}
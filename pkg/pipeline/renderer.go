@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"context"
+)
+
+type (
+	// Renderer turns a pipeline blueprint, or the trace recorded on a context, into diagram source.
+	// Trace returns "" for a context that was never traced.
+	Renderer interface {
+		Blueprint(*Pipeline) string
+		Trace(context.Context) string
+	}
+
+	plantUML struct{}
+)
+
+var (
+	PlantUML Renderer = plantUML{}
+	Mermaid  Renderer = mermaid{}
+)
+
+func (p *Pipeline) Render(r Renderer) string {
+	return r.Blueprint(p)
+}
+
+func RenderTrace(ctx context.Context, r Renderer) string {
+	if disabled(ctx) {
+		return ""
+	}
+
+	return r.Trace(ctx)
+}
+
+func (plantUML) Blueprint(p *Pipeline) string {
+	return p.Diagram()
+}
+
+func (plantUML) Trace(ctx context.Context) string {
+	t, ok := ctx.Value(tracerKey).(*tracer)
+	if !ok {
+		return ""
+	}
+
+	return t.TracedDiagram(TxnID(ctx))
+}
@@ -0,0 +1,81 @@
+package pipeline
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const defaultBackoffMultiplier = 2
+
+type (
+	RetryableFn func(error) bool
+
+	RetryPolicy struct {
+		MaxAttempts int
+		Backoff     time.Duration
+		MaxBackoff  time.Duration
+		Multiplier  float64
+		Jitter      float64
+		Retryable   RetryableFn
+	}
+
+	attempt struct {
+		number    int
+		startTime time.Time
+		endTime   time.Time
+		error     error
+	}
+)
+
+func (r *RetryPolicy) do(ctx context.Context, tracer stopwatch, fn func() error) error {
+	if r == nil {
+		return fn()
+	}
+
+	for number := 1; ; number++ {
+		started := now()
+		err := fn()
+		tracer.attempt(attempt{number: number, startTime: started, endTime: now(), error: err})
+
+		if err == nil || number >= r.MaxAttempts || !r.retryable(err) {
+			return err
+		}
+
+		timer := time.NewTimer(r.delay(number))
+
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+	}
+}
+
+func (r *RetryPolicy) retryable(err error) bool {
+	if r.Retryable == nil {
+		return true
+	}
+
+	return r.Retryable(err)
+}
+
+func (r *RetryPolicy) delay(number int) time.Duration {
+	multiplier := r.Multiplier
+	if multiplier <= 0 {
+		multiplier = defaultBackoffMultiplier
+	}
+
+	backoff := float64(r.Backoff) * math.Pow(multiplier, float64(number-1))
+	if r.MaxBackoff > 0 && backoff > float64(r.MaxBackoff) {
+		backoff = float64(r.MaxBackoff)
+	}
+
+	if r.Jitter > 0 {
+		backoff += backoff * r.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(backoff)
+}
@@ -2,14 +2,20 @@ package pipeline
 
 import (
 	"context"
+	"time"
 )
 
 type (
 	StageFn func(context.Context, interface{}) (interface{}, error)
 
 	SimplePipe struct {
+		Name     string
 		Resolver StageFn
 		Comments string
+		Retry    *RetryPolicy
+		Timeout  time.Duration
+
+		Middlewares []Middleware
 	}
 )
 
@@ -31,30 +37,9 @@ func (sp *SimplePipe) connect(
 
 	panicProof(
 		func() {
-			processData := func(data interface{}) {
-				defer tracer.done()
-				tracer.start(ctx)
-
-				result, err := sp.Resolver(ctx, data)
-				if err != nil {
-					fail(tracer, err, errors, b)
-					return
-				}
-
-				out <- result
-			}
-
-			select {
-			case data, ok := <-in:
-				if ok {
-					processData(data)
-				} else {
-					tracer.canceled()
-				}
-			case <-ctx.Done():
-				tracer.canceled()
-				return
-			}
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				sp.handleInput(ctx, out, errors, tracer, b, data)
+			})
 		},
 		notifyPanicAsError(ctx, errors, b, tracer),
 		closeOutput(out, errors),
@@ -63,8 +48,44 @@ func (sp *SimplePipe) connect(
 	return out, errors
 }
 
+func (sp *SimplePipe) handleInput(
+	ctx context.Context,
+	out chan interface{},
+	errors chan error,
+	tracer stopwatch,
+	b breaker,
+	data interface{},
+) {
+	defer tracer.done()
+	ctx = tracer.start(ctx)
+
+	timer := newStageTimer(ctx, sp, stageName(sp.resolver()), sp.Timeout, tracer)
+	defer timer.stop()
+
+	var result interface{}
+
+	err := sp.Retry.do(timer, tracer, func() (err error) {
+		result, err = bounded(timer, func() (interface{}, error) {
+			return invoke(timer, KindResolver, timer.stage, sp.resolver(), sp.Middlewares, data, sp.Resolver)
+		})
+
+		return err
+	})
+	if err != nil && ctx.Err() != nil {
+		cancel(tracer, err, errors, b)
+		return
+	}
+
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	emit(ctx, out, result)
+}
+
 func (sp *SimplePipe) draw(_ Skin) string {
-	out := drawStage(sp.Resolver)
+	out := drawStage(sp.resolver())
 
 	if sp.Comments != "" {
 		out += "note right \n"
@@ -81,16 +102,21 @@ func (sp *SimplePipe) traced(n *tracerNode) string {
 
 	switch {
 	case n.error != nil:
-		out = drawFailedStage(sp.Resolver, n.error)
+		out = drawFailedStage(sp.resolver(), n.error)
 
 	case n.cancelled:
-		out = drawCanceledStage(sp.Resolver)
+		out = drawCanceledStage(sp.resolver())
 
 	default:
-		out = drawStage(sp.Resolver)
+		out = drawStage(sp.resolver())
 	}
 
+	out += attemptsOf(n)
 	out += notesOf(n)
 
 	return out
 }
+
+func (sp *SimplePipe) resolver() interface{} {
+	return named(sp.Name, sp.Resolver)
+}
@@ -0,0 +1,230 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	spanKey ctxKey = "pipeline.spans"
+
+	pipelineAttr = attribute.Key("pipeline.name")
+	stageAttr    = attribute.Key("pipeline.stage")
+	kindAttr     = attribute.Key("pipeline.kind")
+	branchAttr   = attribute.Key("pipeline.branch")
+	tagAttr      = attribute.Key("pipeline.branch.tag")
+	timeoutAttr  = attribute.Key("pipeline.timeout")
+	canceledAttr = attribute.Key("pipeline.canceled")
+	exitAttr     = attribute.Key("pipeline.early_exit")
+	attemptAttr  = attribute.Key("pipeline.attempt")
+)
+
+type (
+	spans struct {
+		tracer   trace.Tracer
+		pipeline string
+	}
+
+	spanTask struct {
+		stopwatch
+		spans *spans
+		stage string
+		kind  string
+
+		mtx  sync.Mutex
+		span trace.Span
+	}
+)
+
+func withSpans(ctx context.Context, bp *Pipeline) context.Context {
+	tracer := bp.SpanTracer
+
+	if parent, ok := ctx.Value(spanKey).(*spans); ok && tracer == nil {
+		tracer = parent.tracer
+	}
+
+	if tracer == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, spanKey, &spans{tracer: tracer, pipeline: bp.Name})
+}
+
+func spanned(ctx context.Context, pipe Traceable, task stopwatch) stopwatch {
+	s, ok := ctx.Value(spanKey).(*spans)
+	if !ok {
+		return task
+	}
+
+	stage, kind := describe(pipe)
+
+	return &spanTask{stopwatch: task, spans: s, stage: stage, kind: kind}
+}
+
+func (s *spanTask) start(ctx context.Context) context.Context {
+	ctx = s.stopwatch.start(ctx)
+
+	ctx, span := s.spans.tracer.Start(ctx, s.stage, trace.WithAttributes(
+		pipelineAttr.String(s.spans.pipeline),
+		stageAttr.String(s.stage),
+		kindAttr.String(s.kind),
+		branchAttr.String(BranchPath(ctx)),
+	))
+
+	s.mtx.Lock()
+	s.span = span
+	s.mtx.Unlock()
+
+	return ctx
+}
+
+func (s *spanTask) done() {
+	s.stopwatch.done()
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.span != nil {
+		s.span.End()
+		s.span = nil
+	}
+}
+
+func (s *spanTask) canceled() {
+	s.stopwatch.canceled()
+	s.annotate(func(span trace.Span) {
+		span.SetAttributes(canceledAttr.Bool(true))
+	})
+}
+
+func (s *spanTask) fail(err error) {
+	s.stopwatch.fail(err)
+	s.annotate(func(span trace.Span) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	})
+}
+
+func (s *spanTask) attempt(a attempt) {
+	s.stopwatch.attempt(a)
+	s.annotate(func(span trace.Span) {
+		attrs := []attribute.KeyValue{attemptAttr.Int(a.number)}
+		if a.error != nil {
+			attrs = append(attrs, attribute.String("error", a.error.Error()))
+		}
+
+		span.AddEvent("attempt", trace.WithAttributes(attrs...), trace.WithTimestamp(a.endTime))
+	})
+}
+
+func (s *spanTask) limit(timeout time.Duration) {
+	s.stopwatch.limit(timeout)
+	s.annotate(func(span trace.Span) {
+		span.SetAttributes(timeoutAttr.String(timeout.String()))
+	})
+}
+
+func (s *spanTask) exit() {
+	s.stopwatch.exit()
+	s.annotate(func(span trace.Span) {
+		span.SetAttributes(exitAttr.Bool(true))
+	})
+}
+
+func (s *spanTask) annotate(fn func(trace.Span)) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.span != nil {
+		fn(s.span)
+	}
+}
+
+func branchSpan(ctx context.Context) (context.Context, func(error)) {
+	s, ok := ctx.Value(spanKey).(*spans)
+	if !ok {
+		return ctx, nil
+	}
+
+	tag := branchName(ctx)
+
+	ctx, span := s.tracer.Start(ctx, "branch "+tag, trace.WithAttributes(
+		pipelineAttr.String(s.pipeline),
+		kindAttr.String("branch"),
+		branchAttr.String(BranchPath(ctx)),
+		tagAttr.String(tag),
+	))
+
+	return ctx, func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		span.End()
+	}
+}
+
+func endWith(errs []<-chan error, end func(error)) []<-chan error {
+	if end == nil {
+		return errs
+	}
+
+	out := make(chan error, len(errs))
+
+	go func() {
+		defer close(out)
+
+		var first error
+
+		for err := range mergeErrors(errs...) {
+			if first == nil {
+				first = err
+			}
+
+			out <- err
+		}
+
+		end(first)
+	}()
+
+	return []<-chan error{out}
+}
+
+func describe(pipe Traceable) (string, string) {
+	switch p := pipe.(type) {
+	case *SimplePipe:
+		return stageName(p.resolver()), "simple"
+	case *Map:
+		return stageName(p.resolver()), "map"
+	case *Filter:
+		return stageName(p.resolver()), "filter"
+	case *Tap:
+		return stageName(p.resolver()), "tap"
+	case *Broadcast:
+		return p.Name, "broadcast"
+	case *Iterator:
+		return p.Name, "iterator"
+	case *Loop:
+		return p.Name, "loop"
+	case *IfPipe:
+		return p.Name, "if"
+	case *Switch:
+		return p.Name, "switch"
+	case *PartitionPipe:
+		return p.Name, "partition"
+	case *Race:
+		return p.Name, "race"
+	case *Quorum:
+		return p.Name, "quorum"
+	case *SubPipeline:
+		return p.pipeline().Name, "pipeline"
+	default:
+		return "stage", "unknown"
+	}
+}
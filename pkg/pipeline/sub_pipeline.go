@@ -0,0 +1,186 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrNilPipeline = errors.New("sub-pipeline has no Pipeline")
+
+type (
+	SubPipeline struct {
+		Pipeline *Pipeline
+		Linked   bool
+		Timeout  time.Duration
+	}
+)
+
+func (sp *SubPipeline) connect(
+	ctx context.Context,
+	in <-chan interface{},
+	b breaker,
+) (
+	<-chan interface{},
+	<-chan error,
+) {
+	out := make(chan interface{}, cap(in))
+	errors := make(chan error, 1)
+	tracer := traceMe(ctx, sp)
+
+	panicProof(
+		func() {
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				sp.handleInput(ctx, out, errors, tracer, b, data)
+			})
+		},
+		notifyPanicAsError(ctx, errors, b, tracer),
+		closeOutput(out, errors),
+	)
+
+	return out, errors
+}
+
+func (sp *SubPipeline) handleInput(
+	ctx context.Context,
+	out chan interface{},
+	errors chan error,
+	tracer stopwatch,
+	b breaker,
+	data interface{},
+) {
+	defer tracer.done()
+	ctx = tracer.start(ctx)
+
+	if sp.Pipeline == nil {
+		fail(tracer, ErrNilPipeline, errors, b)
+		return
+	}
+
+	timer := newStageTimer(ctx, sp, sp.Pipeline.Name, sp.Timeout, tracer)
+	defer timer.stop()
+
+	// The sub-run gets its own exit scope: an Exit inside it ends the sub-run, whose Sink output then
+	// carries on in the parent flow.
+	sCtx, sb := newBreaker(timer)
+	defer sb.cancel()
+
+	var (
+		p       = sp.Pipeline
+		flowCtx = instrumented(openBranch(CtxBranch(withExit(sCtx, sb), p.Name), sp, p.Name), p)
+	)
+
+	input, err := bounded(timer, func() (interface{}, error) {
+		return resolve(flowCtx, p.Source, data)
+	})
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	r, err := bounded(timer, func() (Result, error) {
+		r, ok := runOn(withBudget(flowCtx, p), branch{ctx: flowCtx, flow: p.Flow, data: input}, sb)
+		if value, exited := sb.exited(); exited {
+			return Result{Value: value}, nil
+		}
+
+		if !ok && r.Err == nil {
+			r.Err = context.Canceled
+		}
+
+		return r, r.Err
+	})
+	if err != nil {
+		timer.abort(tracer, err, errors, b)
+		return
+	}
+
+	output, err := bounded(timer, func() (interface{}, error) {
+		return resolve(flowCtx, p.Sink, r.Value)
+	})
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	emit(ctx, out, output)
+}
+
+func resolve[F ~func(context.Context, interface{}) (interface{}, error)](
+	ctx context.Context,
+	fn F,
+	data interface{},
+) (
+	interface{},
+	error,
+) {
+	if fn == nil {
+		return data, nil
+	}
+
+	return fn(ctx, data)
+}
+
+// pipeline stands an empty, named Pipeline in for a missing one, so diagrams still render the stage.
+func (sp *SubPipeline) pipeline() *Pipeline {
+	if sp.Pipeline == nil {
+		return &Pipeline{Name: "nil pipeline"}
+	}
+
+	return sp.Pipeline
+}
+
+func (sp *SubPipeline) draw(skin Skin) string {
+	p := sp.pipeline()
+
+	if sp.Linked {
+		if sp.Pipeline == nil {
+			return fmt.Sprintf(": ⧉ %s ; \n", p.Name)
+		}
+
+		if l, err := Link(p); err == nil {
+			return fmt.Sprintf(": ⧉ [[%s %s]] ; \n", l, p.Name)
+		}
+
+		return fmt.Sprintf(": ⧉ %s ; \n", p.Name)
+	}
+
+	output := fmt.Sprintf("partition \"%s\" {\n", p.Name)
+	output += drawStage(named(p.SourceName, p.Source))
+
+	for _, pipe := range p.Flow {
+		output += pipe.draw(skin)
+	}
+
+	output += drawStage(named(p.SinkName, p.Sink))
+	output += "}\n"
+
+	return output
+}
+
+func (sp *SubPipeline) traced(n *tracerNode) string {
+	p := sp.pipeline()
+
+	output := fmt.Sprintf("partition \"%s\" {\n", p.Name)
+
+	if n.error != nil {
+		output += fmt.Sprintf(": ☠ %+v; \n", n.error)
+		output += "}\n"
+		output += notesOf(n)
+
+		return output
+	}
+
+	output += drawStage(named(p.SourceName, p.Source))
+
+	for _, b := range n.branches.dump() {
+		output += traceBranch(b)
+	}
+
+	output += drawStage(named(p.SinkName, p.Sink))
+	output += "}\n"
+	output += notesOf(n)
+
+	return output
+}
@@ -0,0 +1,34 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSubPipelineWithoutPipeline(t *testing.T) {
+	bp := &Pipeline{
+		Name:   "outer",
+		Source: passThrough,
+		Sink:   passThrough,
+		Flow:   Flow{&SubPipeline{}},
+	}
+
+	for _, r := range []Renderer{PlantUML, Mermaid} {
+		if d := bp.Render(r); !strings.Contains(d, "nil pipeline") {
+			t.Errorf("expected the missing pipeline in the diagram, got:\n%s", d)
+		}
+	}
+
+	_, trace, err := RunWithTracer(context.Background(), 1, bp)
+	if !errors.Is(err, ErrNilPipeline) {
+		t.Fatalf("expected %v, got %v", ErrNilPipeline, err)
+	}
+
+	for _, r := range []Renderer{PlantUML, Mermaid} {
+		if d := trace.Render(r); !strings.Contains(d, ErrNilPipeline.Error()) {
+			t.Errorf("expected the failure in the traced diagram, got:\n%s", d)
+		}
+	}
+}
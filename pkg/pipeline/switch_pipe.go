@@ -0,0 +1,198 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// defaultCase keys the Default flow's traffic and trace branch. The NUL prefix keeps it apart from any case a
+// Selector can name; diagrams still label it "default".
+const defaultCase = "\x00default"
+
+type (
+	SelectorFn func(context.Context, interface{}) (string, error)
+
+	Switch struct {
+		Name          string
+		Selector      SelectorFn
+		Cases         map[string]Flow
+		Default       Flow
+		TrafficTagger TrafficTagger
+		TrafficWindow time.Duration
+		Timeout       time.Duration
+		Middlewares   []Middleware
+
+		traffic traffic
+	}
+)
+
+func (s *Switch) connect(
+	ctx context.Context,
+	in <-chan interface{},
+	b breaker,
+) (
+	<-chan interface{},
+	<-chan error,
+) {
+	out := make(chan interface{}, cap(in))
+	errors := make(chan error, 1)
+	tracer := traceMe(ctx, s)
+
+	panicProof(
+		func() {
+			consume(ctx, in, out, tracer, b, func(ctx context.Context, b breaker, data interface{}) {
+				s.handleInput(ctx, out, errors, tracer, b, data)
+			})
+		},
+		notifyPanicAsError(ctx, errors, b, tracer),
+		closeOutput(out, errors),
+	)
+
+	return out, errors
+}
+
+func (s *Switch) handleInput(
+	ctx context.Context,
+	out chan interface{},
+	errors chan error,
+	tracer stopwatch,
+	b breaker,
+	data interface{},
+) {
+	defer tracer.done()
+	ctx = tracer.start(ctx)
+
+	pipeIn := make(chan interface{}, 1)
+
+	timer := newStageTimer(ctx, s, s.Name, s.Timeout, tracer)
+	defer timer.stop()
+
+	selected, err := bounded(timer, func() (string, error) {
+		return invoke(timer, KindSelector, s.Name, s.Selector, s.Middlewares, data, s.Selector)
+	})
+	if err != nil && ctx.Err() != nil {
+		cancel(tracer, err, errors, b)
+		return
+	}
+
+	if err != nil {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	sb := b.scope()
+
+	flow, selected := s.selectedFlow(ctx, data, selected)
+	flowCtx := openBranch(CtxBranch(timer, fmt.Sprintf("%s#%s", s.Name, caseName(selected))), s, selected)
+	pOut, pErrs := connectFlow(flowCtx, pipeIn, flow, sb)
+
+	go feed(flowCtx, pipeIn, data)
+
+	_, err = bounded(timer, func() (interface{}, error) {
+		return nil, WaitForPipeline(pErrs...)
+	})
+	if err != nil {
+		timer.abort(tracer, err, errors, b)
+		return
+	}
+
+	done(timer, out, pOut, tracer)
+}
+
+func (s *Switch) selectedFlow(ctx context.Context, data interface{}, selected string) (Flow, string) {
+	flow, ok := s.Cases[selected]
+	if !ok {
+		flow, selected = s.Default, defaultCase
+	}
+
+	s.traffic.count(ctx, selected, s.TrafficTagger, data, s.TrafficWindow)
+
+	return flow, selected
+}
+
+func (s *Switch) Stats() TrafficStats {
+	return s.traffic.stats(s.TrafficWindow)
+}
+
+func (s *Switch) ResetStats() {
+	s.traffic.reset()
+}
+
+func (s *Switch) flows() *traffic {
+	return &s.traffic
+}
+
+func (s *Switch) draw(skin Skin) string {
+	var output string
+
+	var sortedKeys []string
+	for k := range s.Cases {
+		sortedKeys = append(sortedKeys, k)
+	}
+
+	sort.Strings(sortedKeys)
+
+	volume := s.traffic.flowVolume(s.TrafficWindow)
+	output += fmt.Sprintf("switch (%s?)\n", s.Name)
+
+	for _, k := range sortedKeys {
+		output += fmt.Sprintf("case (%s)\n", k)
+		output += drawFlowVolume("left", volume[k])
+
+		for _, pipe := range s.Cases[k] {
+			output += pipe.draw(skin)
+		}
+	}
+
+	output += fmt.Sprintf("case (%s)\n", caseLabel(defaultCase))
+	output += drawFlowVolume("left", volume[defaultCase])
+
+	for _, pipe := range s.Default {
+		output += pipe.draw(skin)
+	}
+
+	output += "endswitch \n"
+
+	return output
+}
+
+func (s *Switch) traced(n *tracerNode) string {
+	var output string
+
+	if n.error != nil {
+		output += fmt.Sprintf(": ♢  ☠ %+v     /\n", n.error)
+		output += notesOf(n)
+
+		return output
+	}
+
+	output += fmt.Sprintf("switch (%s?)\n", s.Name)
+
+	for name, b := range n.branches.dump() {
+		output += fmt.Sprintf("case (%s)\n", caseLabel(name))
+		output += traceBranch(b)
+	}
+
+	output += "endswitch \n"
+	output += notesOf(n)
+
+	return output
+}
+
+func caseLabel(name string) string {
+	if name == defaultCase {
+		return "//" + caseName(name) + "//"
+	}
+
+	return name
+}
+
+func caseName(name string) string {
+	if name == defaultCase {
+		return "default"
+	}
+
+	return name
+}
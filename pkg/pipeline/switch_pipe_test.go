@@ -0,0 +1,40 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSwitchDefaultDoesNotCollideWithCase(t *testing.T) {
+	tag := func(label string) Flow {
+		return Flow{Stage(func(_ context.Context, data interface{}) (interface{}, error) {
+			return data.(string) + ":" + label, nil
+		})}
+	}
+
+	sw := &Switch{
+		Name: "route",
+		Selector: func(_ context.Context, data interface{}) (string, error) {
+			return data.(string), nil
+		},
+		Cases:   map[string]Flow{"default": tag("case")},
+		Default: tag("fallback"),
+	}
+
+	bp := &Pipeline{Name: "switch", Source: passThrough, Sink: passThrough, Flow: Flow{sw}}
+
+	for in, want := range map[string]string{"default": "default:case", "other": "other:fallback"} {
+		out, err := Run(context.Background(), in, bp, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if out != want {
+			t.Errorf("%s: expected %q, got %v", in, want, out)
+		}
+	}
+
+	if branches := sw.Stats().Branches; len(branches) != 2 || branches["default"].Total != 1 {
+		t.Errorf("expected the case and the Default flow to be counted apart, got %+v", branches)
+	}
+}
@@ -0,0 +1,147 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const budgetKey ctxKey = "pipeline.budget"
+
+type (
+	ErrStageTimeout struct {
+		Stage   string
+		Timeout time.Duration
+	}
+
+	budget struct {
+		deadline time.Time
+		stages   Flow
+	}
+
+	stageTimer struct {
+		context.Context
+		parent  context.Context
+		stage   string
+		timeout time.Duration
+		stop    context.CancelFunc
+	}
+)
+
+func (e *ErrStageTimeout) Error() string {
+	return fmt.Sprintf("stage %s timed out after %v", e.Stage, e.Timeout)
+}
+
+func (e *ErrStageTimeout) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+func withBudget(ctx context.Context, bp *Pipeline) context.Context {
+	if bp.Budget <= 0 {
+		return ctx
+	}
+
+	return context.WithValue(ctx, budgetKey, &budget{deadline: now().Add(bp.Budget), stages: bp.Flow})
+}
+
+func (b *budget) share(pipe Pipe) time.Duration {
+	for idx, stage := range b.stages {
+		if stage != pipe {
+			continue
+		}
+
+		left := time.Until(b.deadline)
+		if left <= 0 {
+			return time.Nanosecond
+		}
+
+		return left / time.Duration(len(b.stages)-idx)
+	}
+
+	return 0
+}
+
+func newStageTimer(
+	ctx context.Context,
+	pipe Pipe,
+	stage string,
+	timeout time.Duration,
+	tracer stopwatch,
+) *stageTimer {
+	if b, ok := ctx.Value(budgetKey).(*budget); ok && timeout <= 0 {
+		timeout = b.share(pipe)
+	}
+
+	if timeout <= 0 {
+		return &stageTimer{Context: ctx, parent: ctx, stage: stage, stop: func() {}}
+	}
+
+	tracer.limit(timeout)
+	tCtx, stop := context.WithTimeout(ctx, timeout)
+
+	return &stageTimer{Context: tCtx, parent: ctx, stage: stage, timeout: timeout, stop: stop}
+}
+
+func (t *stageTimer) expired() bool {
+	return t.timeout > 0 &&
+		errors.Is(t.Err(), context.DeadlineExceeded) &&
+		t.parent.Err() == nil
+}
+
+func (t *stageTimer) check(err error) error {
+	if t.expired() {
+		return &ErrStageTimeout{Stage: t.stage, Timeout: t.timeout}
+	}
+
+	return err
+}
+
+func bounded[T any](t *stageTimer, fn func() (T, error)) (T, error) {
+	if t.timeout <= 0 {
+		return fn()
+	}
+
+	type outcome struct {
+		value T
+		err   error
+	}
+
+	result := make(chan outcome, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				result <- outcome{err: fmt.Errorf("panic recovered: %+v", p)}
+			}
+		}()
+
+		value, err := fn()
+		result <- outcome{value: value, err: err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.value, t.check(r.err)
+
+	case <-t.Done():
+		var zero T
+		return zero, t.check(t.Err())
+	}
+}
+
+func (t *stageTimer) abort(tracer stopwatch, err error, errors chan error, b breaker) {
+	if t.expired() {
+		fail(tracer, err, errors, b)
+		return
+	}
+
+	cancel(tracer, err, errors, b)
+}
+
+func stageName(r interface{}) string {
+	_, parts := resolverName(r)
+
+	return strings.Replace(strings.Join(parts, "."), "-fm", "", 1)
+}
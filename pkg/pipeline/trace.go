@@ -0,0 +1,160 @@
+package pipeline
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+const branchSeparator = "/"
+
+type (
+	// Trace is the structured form of a traced run, safe to marshal, store and diff.
+	Trace struct {
+		TxnID        string        `json:"txn_id"`
+		Link         string        `json:"link,omitempty"`
+		LinkError    string        `json:"link_error,omitempty"`
+		Pipeline     string        `json:"pipeline"`
+		Start        time.Time     `json:"start"`
+		End          time.Time     `json:"end"`
+		Duration     time.Duration `json:"duration_ns"`
+		SourceNotes  []string      `json:"source_notes,omitempty"`
+		SinkNotes    []string      `json:"sink_notes,omitempty"`
+		SourceExited bool          `json:"source_exited,omitempty"`
+		Stages       []TraceStage  `json:"stages"`
+		Analysis     *Analysis     `json:"analysis,omitempty"`
+
+		tracer *tracer
+	}
+
+	TraceStage struct {
+		Kind     string         `json:"kind"`
+		Name     string         `json:"name"`
+		Branch   string         `json:"branch,omitempty"`
+		Start    time.Time      `json:"start"`
+		End      time.Time      `json:"end"`
+		Duration time.Duration  `json:"duration_ns"`
+		Timeout  time.Duration  `json:"timeout_ns,omitempty"`
+		Canceled bool           `json:"canceled,omitempty"`
+		Exited   bool           `json:"exited,omitempty"`
+		Error    string         `json:"error,omitempty"`
+		Notes    []string       `json:"notes,omitempty"`
+		Attempts []TraceAttempt `json:"attempts,omitempty"`
+		Branches []TraceBranch  `json:"branches,omitempty"`
+
+		node *tracerNode
+	}
+
+	TraceBranch struct {
+		Name   string       `json:"name"`
+		Path   string       `json:"path"`
+		Stages []TraceStage `json:"stages"`
+	}
+
+	TraceAttempt struct {
+		Number   int           `json:"number"`
+		Start    time.Time     `json:"start"`
+		End      time.Time     `json:"end"`
+		Duration time.Duration `json:"duration_ns"`
+		Error    string        `json:"error,omitempty"`
+	}
+)
+
+func traceOf(ctx context.Context) *Trace {
+	t := ctx.Value(tracerKey).(*tracer)
+
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+
+	end := t.finish()
+
+	return &Trace{
+		TxnID:        TxnID(ctx),
+		Pipeline:     t.name,
+		Start:        t.start,
+		End:          end,
+		Duration:     end.Sub(t.start),
+		SourceNotes:  t.sourceNotes,
+		SinkNotes:    t.sinkNotes,
+		SourceExited: t.sourceExited,
+		Stages:       exportBranch("", t.nodes),
+		tracer:       t,
+	}
+}
+
+// Render draws the traced run with r, e.g. Trace.Render(Mermaid), long after RunWithTracer returned.
+func (t *Trace) Render(r Renderer) string {
+	if t.tracer == nil {
+		return ""
+	}
+
+	return r.Trace(t.tracer.context())
+}
+
+func exportBranch(path string, nodes []*tracerNode) []TraceStage {
+	stages := make([]TraceStage, 0, len(nodes))
+
+	for _, n := range nodes {
+		n.mtx.Lock()
+		stages = append(stages, exportNode(path, n))
+		n.mtx.Unlock()
+	}
+
+	return stages
+}
+
+func exportNode(path string, n *tracerNode) TraceStage {
+	name, kind := describe(n.pipe)
+
+	stage := TraceStage{
+		Kind:     kind,
+		Name:     name,
+		Branch:   path,
+		Start:    n.startTime,
+		End:      n.endTime,
+		Timeout:  n.timeout,
+		Canceled: n.cancelled,
+		Exited:   n.exited,
+		Notes:    n.annotations.notes,
+		node:     n,
+	}
+
+	if !n.startTime.IsZero() && !n.endTime.IsZero() {
+		stage.Duration = n.endTime.Sub(n.startTime)
+	}
+
+	if n.error != nil {
+		stage.Error = n.error.Error()
+	}
+
+	for _, a := range n.attempts {
+		attempt := TraceAttempt{Number: a.number, Start: a.startTime, End: a.endTime, Duration: a.endTime.Sub(a.startTime)}
+		if a.error != nil {
+			attempt.Error = a.error.Error()
+		}
+
+		stage.Attempts = append(stage.Attempts, attempt)
+	}
+
+	branches := n.branches.dump()
+
+	names := chronological(branches)
+	if kind != "loop" {
+		sort.Strings(names)
+	}
+
+	for _, name := range names {
+		bPath := name
+		if path != "" {
+			bPath = path + branchSeparator + name
+		}
+
+		stage.Branches = append(stage.Branches, TraceBranch{
+			Name:   caseName(name),
+			Path:   bPath,
+			Stages: exportBranch(bPath, branches[name]),
+		})
+	}
+
+	return stage
+}
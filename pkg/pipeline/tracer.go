@@ -14,6 +14,8 @@ const (
 	tracerKey
 )
 
+const runningKey ctxKey = "pipeline.running"
+
 var mark = struct{}{}
 
 type (
@@ -21,9 +23,12 @@ type (
 
 	stopwatch interface {
 		done()
-		start(context.Context)
+		start(context.Context) context.Context
 		canceled()
 		fail(error)
+		attempt(attempt)
+		limit(time.Duration)
+		exit()
 	}
 
 	tracer struct {
@@ -35,8 +40,11 @@ type (
 
 		skin Skin
 
-		sourceNotes []string
-		sinkNotes   []string
+		sourceNotes  []string
+		sinkNotes    []string
+		sourceExited bool
+
+		txn string
 	}
 
 	annotations struct {
@@ -52,9 +60,17 @@ type (
 		endTime     time.Time
 		error       error
 		cancelled   bool
+		attempts    []attempt
+		timeout     time.Duration
+		exited      bool
+		critical    bool
 		annotations *annotations
 	}
 
+	// runningTask keeps the stage's full stopwatch in the context it runs with, so an Exit from inside
+	// the stage goes through every decorator.
+	runningTask struct{ stopwatch }
+
 	dummyTask    struct{}
 	dummyJotter  struct{}
 	sourceJotter struct{ tracer *tracer }
@@ -136,7 +152,7 @@ func TracerOn(ctx context.Context) bool {
 func newTracer(ctx context.Context, p *Pipeline) context.Context {
 	var (
 		pointer    = &TracerPointer{name: "root"}
-		rootTracer = root(p)
+		rootTracer = root(p, resolveTxnID(ctx))
 
 		pointerCtx = context.WithValue(ctx, rootTracer.stagePointer(), pointer)
 		tracerCtx  = context.WithValue(pointerCtx, tracerKey, rootTracer)
@@ -145,6 +161,20 @@ func newTracer(ctx context.Context, p *Pipeline) context.Context {
 	return context.WithValue(tracerCtx, tracerEnabledKey, mark)
 }
 
+// finish stamps the end of the run the first time it is asked for, so later renders keep the run's own duration.
+func (t *tracer) finish() time.Time {
+	if t.end.IsZero() {
+		t.end = now()
+	}
+
+	return t.end
+}
+
+// context gives back a context carrying the tracer alone, enough to render it once the run is over.
+func (t *tracer) context() context.Context {
+	return context.WithValue(context.WithValue(context.Background(), tracerKey, t), tracerEnabledKey, mark)
+}
+
 func (t *tracer) traceExecution(ctx context.Context, node *tracerNode) {
 	t.mtx.Lock()
 	defer t.mtx.Unlock()
@@ -192,15 +222,21 @@ func (t *tracer) findAnnotationsOf(ctx context.Context) *tracerNode {
 
 	pointer := ctx.Value(t.stagePointer()).(*TracerPointer)
 
+	nodes := t.nodes
+
 	if n := ctx.Value(t.currentBranchKey()); n != nil {
 		b := n.(string)
 		if b != "" {
 			c := ctx.Value(t.currentTracerKey()).(*tracerNode)
-			return c.branches.get(b)[pointer.pointer-1]
+			nodes = c.branches.get(b)
 		}
 	}
 
-	return t.nodes[pointer.pointer-1]
+	if pointer.pointer < 1 || pointer.pointer > len(nodes) {
+		return nil
+	}
+
+	return nodes[pointer.pointer-1]
 }
 
 func (t *tracer) currentTracerKey() ctxKeysType {
@@ -215,7 +251,7 @@ func (t *tracer) stagePointer() ctxKeysType {
 	return ctxKeysType(fmt.Sprintf("%v.pointer", t.name))
 }
 
-func (n *tracerNode) start(ctx context.Context) {
+func (n *tracerNode) start(ctx context.Context) context.Context {
 	defer n.mtx.Unlock()
 
 	t := ctx.Value(tracerKey).(*tracer)
@@ -223,6 +259,8 @@ func (n *tracerNode) start(ctx context.Context) {
 	n.mtx.Lock()
 	n.startTime = now()
 	ctx.Value(t.stagePointer()).(*TracerPointer).pointer++
+
+	return ctx
 }
 
 func (n *tracerNode) done() {
@@ -248,19 +286,63 @@ func (n *tracerNode) fail(err error) {
 	n.error = err
 }
 
+func (n *tracerNode) attempt(a attempt) {
+	defer n.mtx.Unlock()
+
+	n.mtx.Lock()
+	n.attempts = append(n.attempts, a)
+}
+
+func (n *tracerNode) limit(timeout time.Duration) {
+	defer n.mtx.Unlock()
+
+	n.mtx.Lock()
+	n.timeout = timeout
+}
+
+func (n *tracerNode) exit() {
+	defer n.mtx.Unlock()
+
+	n.mtx.Lock()
+	n.exited = true
+}
+
+func traceExit(ctx context.Context) {
+	if task, ok := ctx.Value(runningKey).(stopwatch); ok {
+		task.exit()
+		return
+	}
+
+	if disabled(ctx) {
+		return
+	}
+
+	t := ctx.Value(tracerKey).(*tracer)
+
+	t.mtx.Lock()
+	t.sourceExited = true
+	t.mtx.Unlock()
+}
+
 func traceMe(ctx context.Context, pipe Traceable) stopwatch {
 	if disabled(ctx) {
-		return &dummyTask{}
+		return &runningTask{spanned(ctx, pipe, metered(ctx, pipe, timed(ctx, pipe, &dummyTask{})))}
 	}
 
 	node := newNode(pipe)
 	tracer := ctx.Value(tracerKey).(*tracer)
 	tracer.traceExecution(ctx, node)
 
-	return node
+	return &runningTask{spanned(ctx, pipe, metered(ctx, pipe, timed(ctx, pipe, node)))}
+}
+
+func (r *runningTask) start(ctx context.Context) context.Context {
+	return context.WithValue(r.stopwatch.start(ctx), runningKey, r.stopwatch)
 }
 
 func openBranch(ctx context.Context, root Traceable, name string) context.Context {
+	ctx = withBranchPath(ctx, name)
+
 	if disabled(ctx) {
 		return ctx
 	}
@@ -277,7 +359,7 @@ func openBranch(ctx context.Context, root Traceable, name string) context.Contex
 	return context.WithValue(pointerCtx, t.currentTracerKey(), rootNode)
 }
 
-func root(p *Pipeline) *tracer {
+func root(p *Pipeline, txn string) *tracer {
 	if p.Name == "" {
 		p.Name = fmt.Sprintf("[no title for %v]", &p)
 	}
@@ -288,6 +370,7 @@ func root(p *Pipeline) *tracer {
 		mtx:   &sync.Mutex{},
 		start: now(),
 		skin:  p.TraceSkin,
+		txn:   txn,
 	}
 }
 
@@ -330,12 +413,15 @@ func annotation(pipe Traceable) *annotations {
 	}
 }
 
-func (*dummyTask) start(context.Context)    {}
-func (*dummyTask) done()                    {}
-func (*dummyTask) canceled()                {}
-func (*dummyTask) fail(error)               {}
-func (*dummyJotter) Note(string)            {}
-func (*dummyJotter) LazyNote(func() string) {}
+func (*dummyTask) start(ctx context.Context) context.Context { return ctx }
+func (*dummyTask) done()                                     {}
+func (*dummyTask) canceled()                                 {}
+func (*dummyTask) fail(error)                                {}
+func (*dummyTask) attempt(attempt)                           {}
+func (*dummyTask) limit(time.Duration)                       {}
+func (*dummyTask) exit()                                     {}
+func (*dummyJotter) Note(string)                             {}
+func (*dummyJotter) LazyNote(func() string)                  {}
 
 func newTracerNodes() *tracerNodes {
 	return &tracerNodes{mtx: &sync.Mutex{}}
@@ -0,0 +1,251 @@
+package pipeline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const windowBuckets = 12
+
+type (
+	TrafficStats struct {
+		Since    time.Time              `json:"since"`
+		Window   time.Duration          `json:"window,omitempty"`
+		Total    uint64                 `json:"total"`
+		Branches map[string]BranchStats `json:"branches"`
+	}
+
+	BranchStats struct {
+		Total   uint64            `json:"total"`
+		Percent float64           `json:"percent"`
+		Tags    map[string]uint64 `json:"tags,omitempty"`
+	}
+
+	traffic struct {
+		mtx      sync.Mutex
+		since    time.Time
+		counters map[string]*flowCounter
+		buckets  []bucket
+		lifetime map[string]*flowCounter
+	}
+
+	bucket struct {
+		start    time.Time
+		counters map[string]*flowCounter
+	}
+
+	counted interface {
+		Pipe
+		Stats() TrafficStats
+		ResetStats()
+		flows() *traffic
+	}
+)
+
+func (t *traffic) count(ctx context.Context, path string, tagger TrafficTagger, data interface{}, window time.Duration) {
+	var tag string
+	if tagger != nil {
+		tag = tagger(ctx, data)
+	}
+
+	defer t.mtx.Unlock()
+	t.mtx.Lock()
+
+	if t.since.IsZero() {
+		t.since = now()
+	}
+
+	if t.lifetime == nil {
+		t.lifetime = make(map[string]*flowCounter)
+	}
+
+	increment(t.lifetime, path, tag, tagger != nil)
+
+	if window <= 0 {
+		if t.counters == nil {
+			t.counters = make(map[string]*flowCounter)
+		}
+
+		increment(t.counters, path, tag, tagger != nil)
+
+		return
+	}
+
+	t.prune(window)
+
+	current := now()
+	if len(t.buckets) == 0 || current.Sub(t.buckets[len(t.buckets)-1].start) >= window/windowBuckets {
+		t.buckets = append(t.buckets, bucket{start: current, counters: make(map[string]*flowCounter)})
+	}
+
+	increment(t.buckets[len(t.buckets)-1].counters, path, tag, tagger != nil)
+}
+
+func increment(counters map[string]*flowCounter, path, tag string, tagged bool) {
+	counter, ok := counters[path]
+	if !ok {
+		counter = &flowCounter{tagged: make(map[string]uint64)}
+		counters[path] = counter
+	}
+
+	if tagged {
+		counter.tagged[tag]++
+	}
+
+	counter.total++
+}
+
+func (t *traffic) prune(window time.Duration) {
+	var (
+		oldest = now().Add(-window)
+		idx    int
+	)
+
+	for idx < len(t.buckets) && t.buckets[idx].start.Before(oldest) {
+		idx++
+	}
+
+	t.buckets = t.buckets[idx:]
+}
+
+func (t *traffic) snapshot(window time.Duration) map[string]*flowCounter {
+	if window <= 0 {
+		return t.counters
+	}
+
+	t.prune(window)
+
+	out := make(map[string]*flowCounter)
+
+	for _, b := range t.buckets {
+		for path, c := range b.counters {
+			merged, ok := out[path]
+			if !ok {
+				merged = &flowCounter{tagged: make(map[string]uint64)}
+				out[path] = merged
+			}
+
+			merged.total += c.total
+			for tag, v := range c.tagged {
+				merged.tagged[tag] += v
+			}
+		}
+	}
+
+	return out
+}
+
+func (t *traffic) flowVolume(window time.Duration) map[string]flowsPercent {
+	defer t.mtx.Unlock()
+	t.mtx.Lock()
+
+	out := make(map[string]flowsPercent)
+
+	counters := t.snapshot(window)
+	if counters == nil {
+		return out
+	}
+
+	var global uint64
+	for _, v := range counters {
+		global += v.total
+	}
+
+	for k, v := range counters {
+		branchCounter := v.total
+
+		percentMap := flowsPercent{
+			total:  (float32(branchCounter) / float32(global)) * oneHundred,
+			tagged: make(map[string]float32),
+		}
+
+		for kk, vv := range v.tagged {
+			percentMap.tagged[kk] = (float32(vv) / float32(branchCounter)) * oneHundred
+		}
+
+		out[k] = percentMap
+	}
+
+	return out
+}
+
+func (t *traffic) stats(window time.Duration) TrafficStats {
+	defer t.mtx.Unlock()
+	t.mtx.Lock()
+
+	out := TrafficStats{Since: t.since, Window: window, Branches: make(map[string]BranchStats)}
+
+	if start := now().Add(-window); window > 0 && start.After(t.since) {
+		out.Since = start
+	}
+
+	counters := t.snapshot(window)
+
+	for _, v := range counters {
+		out.Total += v.total
+	}
+
+	for k, v := range counters {
+		branch := BranchStats{Total: v.total}
+
+		if out.Total > 0 {
+			branch.Percent = float64(v.total) / float64(out.Total) * oneHundred
+		}
+
+		if len(v.tagged) > 0 {
+			branch.Tags = make(map[string]uint64, len(v.tagged))
+			for tag, count := range v.tagged {
+				branch.Tags[tag] = count
+			}
+		}
+
+		out.Branches[k] = branch
+	}
+
+	return out
+}
+
+func (t *traffic) reset() {
+	defer t.mtx.Unlock()
+	t.mtx.Lock()
+
+	t.since = now()
+	t.counters = nil
+	t.buckets = nil
+}
+
+func (t *traffic) totals(fn func(path string, counter flowCounter)) {
+	defer t.mtx.Unlock()
+	t.mtx.Lock()
+
+	for path, c := range t.lifetime {
+		tagged := make(map[string]uint64, len(c.tagged))
+		for tag, v := range c.tagged {
+			tagged[tag] = v
+		}
+
+		fn(path, flowCounter{total: c.total, tagged: tagged})
+	}
+}
+
+func (p *Pipeline) TrafficStats() map[string]TrafficStats {
+	out := make(map[string]TrafficStats)
+
+	walk(p.Flow, func(pipe Pipe) {
+		if c, ok := pipe.(counted); ok {
+			stage, _ := describe(pipe)
+			out[stage] = c.Stats()
+		}
+	})
+
+	return out
+}
+
+func (p *Pipeline) ResetTrafficStats() {
+	walk(p.Flow, func(pipe Pipe) {
+		if c, ok := pipe.(counted); ok {
+			c.ResetStats()
+		}
+	})
+}
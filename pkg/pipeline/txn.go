@@ -0,0 +1,46 @@
+package pipeline
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	requestIDKey ctxKey = "pipeline.request.id"
+)
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// TxnID returns the transaction id of the traced run ctx belongs to. Outside a traced run it falls back to the
+// OTel trace id or the request id on ctx, and to "" when neither is set.
+func TxnID(ctx context.Context) string {
+	if t, ok := ctx.Value(tracerKey).(*tracer); ok && !disabled(ctx) {
+		return t.txn
+	}
+
+	return txnOf(ctx)
+}
+
+func resolveTxnID(ctx context.Context) string {
+	if id := txnOf(ctx); id != "" {
+		return id
+	}
+
+	return uuid.NewString()
+}
+
+func txnOf(ctx context.Context) string {
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+
+	id, _ := ctx.Value(requestIDKey).(string)
+
+	return id
+}
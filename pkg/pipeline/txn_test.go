@@ -0,0 +1,19 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTxnIDWithoutTracer(t *testing.T) {
+	ctx := context.Background()
+
+	if id := TxnID(ctx); id != "" {
+		t.Errorf("expected no txn id on an untraced ctx, got %q", id)
+	}
+
+	ctx = WithRequestID(ctx, "req-1")
+	if first, second := TxnID(ctx), TxnID(ctx); first != "req-1" || second != first {
+		t.Errorf("expected the request id on every call, got %q and %q", first, second)
+	}
+}
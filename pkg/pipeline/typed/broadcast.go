@@ -0,0 +1,48 @@
+package typed
+
+import (
+	"time"
+
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
+)
+
+type (
+	Broadcast[In, Res, Out any] struct {
+		Name     string
+		Label    string
+		Comments string
+
+		Streams []Segment[In, Res]
+		Merger  FanInFn[Res, Out]
+		Timeout time.Duration
+
+		// ErrorPolicy may be FailFast or BestEffort: CollectAll hands the Merger pipeline.Result values, so it needs the untyped Broadcast.
+		ErrorPolicy pipeline.ErrorPolicy
+		Middlewares []pipeline.Middleware
+	}
+)
+
+func (b *Broadcast[In, Res, Out]) pipes() pipeline.Flow {
+	streams := make([]pipeline.Flow, len(b.Streams))
+	for idx, stream := range b.Streams {
+		streams[idx] = stream.pipes()
+	}
+
+	return pipeline.Flow{
+		&pipeline.Broadcast{
+			Name:     b.Name,
+			Label:    b.Label,
+			Comments: b.Comments,
+			Streams:  streams,
+			Merger:   eraseFanIn(b.Merger),
+			Timeout:  b.Timeout,
+
+			ErrorPolicy: b.ErrorPolicy,
+			Middlewares: b.Middlewares,
+		},
+	}
+}
+
+func (*Broadcast[In, Res, Out]) signature(In) (out Out) {
+	return out
+}
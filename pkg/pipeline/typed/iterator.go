@@ -0,0 +1,48 @@
+package typed
+
+import (
+	"time"
+
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
+)
+
+type (
+	Iterator[In, Item, Res, Out any] struct {
+		Name       string
+		MaxP       *int
+		Scheduling pipeline.Scheduling
+		Splitter   FanOutFn[In, Item]
+		Stream     Segment[Item, Res]
+		Joiner     JoinerFn[In, Res, Out]
+		Tagger     BranchTagger[Item]
+		Retry      *pipeline.RetryPolicy
+		Timeout    time.Duration
+
+		// ErrorPolicy may be FailFast or BestEffort: CollectAll hands the Joiner pipeline.Result values, so it needs the untyped Iterator.
+		ErrorPolicy pipeline.ErrorPolicy
+		Middlewares []pipeline.Middleware
+	}
+)
+
+func (i *Iterator[In, Item, Res, Out]) pipes() pipeline.Flow {
+	return pipeline.Flow{
+		&pipeline.Iterator{
+			Name:       i.Name,
+			MaxP:       i.MaxP,
+			Scheduling: i.Scheduling,
+			Splitter:   eraseFanOut(i.Splitter),
+			Stream:     i.Stream.pipes(),
+			Joiner:     eraseJoiner(i.Joiner),
+			Tagger:     eraseTagger(i.Tagger),
+			Retry:      i.Retry,
+			Timeout:    i.Timeout,
+
+			ErrorPolicy: i.ErrorPolicy,
+			Middlewares: i.Middlewares,
+		},
+	}
+}
+
+func (*Iterator[In, Item, Res, Out]) signature(In) (out Out) {
+	return out
+}
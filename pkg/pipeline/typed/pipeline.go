@@ -0,0 +1,156 @@
+package typed
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	sourceName = "source"
+	sinkName   = "sink"
+)
+
+type (
+	Pipeline[In, Out any] struct {
+		Name           string
+		Description    string
+		SourceComments string
+		SinkComments   string
+
+		Source StageFn[In, In]
+		Flow   Segment[In, Out]
+		Sink   StageFn[Out, Out]
+		Budget time.Duration
+
+		Middlewares []pipeline.Middleware
+		SpanTracer  trace.Tracer
+		Meter       metric.Meter
+
+		BlueprintSkin pipeline.Skin
+		TraceSkin     pipeline.Skin
+
+		once     sync.Once
+		compiled *pipeline.Pipeline
+	}
+
+	Result[Out any] struct {
+		Value Out
+		Err   error
+		Seq   int
+	}
+)
+
+func Run[In, Out any](ctx context.Context, input In, p *Pipeline[In, Out], traced bool) (Out, error) {
+	out, err := pipeline.Run(ctx, input, p.Untyped(), traced)
+	if err != nil {
+		var zero Out
+		return zero, err
+	}
+
+	return cast[Out](out)
+}
+
+func RunWithTracer[In, Out any](ctx context.Context, input In, p *Pipeline[In, Out]) (Out, *pipeline.Trace, error) {
+	out, trace, err := pipeline.RunWithTracer(ctx, input, p.Untyped())
+	if err != nil {
+		var zero Out
+		return zero, trace, err
+	}
+
+	typedOut, err := cast[Out](out)
+
+	return typedOut, trace, err
+}
+
+func RunStream[In, Out any](ctx context.Context, in <-chan In, p *Pipeline[In, Out]) <-chan Result[Out] {
+	var (
+		untyped = make(chan interface{})
+		results = make(chan Result[Out])
+	)
+
+	go func() {
+		defer close(untyped)
+
+		for input := range in {
+			select {
+			case untyped <- input:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(results)
+
+		for res := range pipeline.RunStream(ctx, untyped, p.Untyped()) {
+			out := Result[Out]{Err: res.Err, Seq: res.Seq}
+			if res.Err == nil {
+				out.Value, out.Err = cast[Out](res.Value)
+			}
+
+			select {
+			case results <- out:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return results
+}
+
+func (p *Pipeline[In, Out]) Untyped() *pipeline.Pipeline {
+	p.once.Do(func() {
+		p.compiled = &pipeline.Pipeline{
+			Name:           p.Name,
+			Description:    p.Description,
+			SourceComments: p.SourceComments,
+			SinkComments:   p.SinkComments,
+			SourceName:     sourceName,
+			SinkName:       sinkName,
+			Source:         passThrough,
+			Flow:           p.Flow.pipes(),
+			Sink:           passThrough,
+			Budget:         p.Budget,
+			Middlewares:    p.Middlewares,
+			SpanTracer:     p.SpanTracer,
+			Meter:          p.Meter,
+			BlueprintSkin:  p.BlueprintSkin,
+			TraceSkin:      p.TraceSkin,
+		}
+
+		if p.Source != nil {
+			p.compiled.SourceName = funcName(p.Source)
+			p.compiled.Source = pipeline.SourceFn(eraseStage(p.Source))
+		}
+
+		if p.Sink != nil {
+			p.compiled.SinkName = funcName(p.Sink)
+			p.compiled.Sink = pipeline.SinkFn(eraseStage(p.Sink))
+		}
+	})
+
+	return p.compiled
+}
+
+func (p *Pipeline[In, Out]) Diagram() string {
+	return p.Untyped().Diagram()
+}
+
+func (p *Pipeline[In, Out]) Render(r pipeline.Renderer) string {
+	return p.Untyped().Render(r)
+}
+
+func (p *Pipeline[In, Out]) DOT() string {
+	return p.Untyped().DOT()
+}
+
+func passThrough(_ context.Context, input interface{}) (interface{}, error) {
+	return input, nil
+}
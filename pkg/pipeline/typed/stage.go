@@ -0,0 +1,36 @@
+package typed
+
+import (
+	"time"
+
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
+)
+
+type (
+	Stage[In, Out any] struct {
+		Resolver StageFn[In, Out]
+		Comments string
+		Retry    *pipeline.RetryPolicy
+		Timeout  time.Duration
+
+		Middlewares []pipeline.Middleware
+	}
+)
+
+func (s *Stage[In, Out]) pipes() pipeline.Flow {
+	return pipeline.Flow{
+		&pipeline.SimplePipe{
+			Name:     funcName(s.Resolver),
+			Resolver: eraseStage(s.Resolver),
+			Comments: s.Comments,
+			Retry:    s.Retry,
+			Timeout:  s.Timeout,
+
+			Middlewares: s.Middlewares,
+		},
+	}
+}
+
+func (*Stage[In, Out]) signature(In) (out Out) {
+	return out
+}
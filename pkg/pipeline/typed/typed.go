@@ -0,0 +1,160 @@
+// Package typed is a generics-based layer over pkg/pipeline: every pipe declares
+// its input and output types, so wiring mismatched stages fails at compile time
+// instead of at runtime. Typed pipes compile down to the regular pipeline pipes.
+package typed
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime"
+
+	"github.com/antorpo/os-go-concurrency/pkg/pipeline"
+)
+
+type (
+	StageFn[In, Out any]       func(context.Context, In) (Out, error)
+	FanOutFn[In, Item any]     func(context.Context, In) ([]Item, error)
+	FanInFn[Res, Out any]      func(context.Context, []Res) (Out, error)
+	JoinerFn[In, Res, Out any] func(context.Context, In, []Res) (Out, error)
+	BranchTagger[Item any]     func(context.Context, Item) string
+
+	Segment[In, Out any] interface {
+		pipes() pipeline.Flow
+		signature(In) Out
+	}
+
+	Flow[In, Out any] struct {
+		flow pipeline.Flow
+	}
+)
+
+func Then[A, B, C any](first Segment[A, B], next Segment[B, C]) *Flow[A, C] {
+	flow := append(pipeline.Flow{}, first.pipes()...)
+
+	return &Flow[A, C]{flow: append(flow, next.pipes()...)}
+}
+
+func Wrap[In, Out any](pipes ...pipeline.Pipe) *Flow[In, Out] {
+	return &Flow[In, Out]{flow: pipes}
+}
+
+func (f *Flow[In, Out]) pipes() pipeline.Flow {
+	return f.flow
+}
+
+func (*Flow[In, Out]) signature(In) (out Out) {
+	return out
+}
+
+func eraseStage[In, Out any](fn StageFn[In, Out]) pipeline.StageFn {
+	return func(ctx context.Context, input interface{}) (interface{}, error) {
+		in, err := cast[In](input)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(ctx, in)
+	}
+}
+
+func eraseFanOut[In, Item any](fn FanOutFn[In, Item]) pipeline.FanOutFn {
+	return func(ctx context.Context, input interface{}) ([]interface{}, error) {
+		in, err := cast[In](input)
+		if err != nil {
+			return nil, err
+		}
+
+		items, err := fn(ctx, in)
+		if err != nil {
+			return nil, err
+		}
+
+		out := make([]interface{}, len(items))
+		for idx, item := range items {
+			out[idx] = item
+		}
+
+		return out, nil
+	}
+}
+
+func eraseFanIn[Res, Out any](fn FanInFn[Res, Out]) pipeline.FanInFn {
+	return func(ctx context.Context, input []interface{}) (interface{}, error) {
+		results, err := castAll[Res](input)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(ctx, results)
+	}
+}
+
+func eraseJoiner[In, Res, Out any](fn JoinerFn[In, Res, Out]) pipeline.JoinerFn {
+	return func(ctx context.Context, input interface{}, joined []interface{}) (interface{}, error) {
+		in, err := cast[In](input)
+		if err != nil {
+			return nil, err
+		}
+
+		results, err := castAll[Res](joined)
+		if err != nil {
+			return nil, err
+		}
+
+		return fn(ctx, in, results)
+	}
+}
+
+func eraseTagger[Item any](fn BranchTagger[Item]) pipeline.BranchTagger {
+	if fn == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, input interface{}) string {
+		item, err := cast[Item](input)
+		if err != nil {
+			return err.Error()
+		}
+
+		return fn(ctx, item)
+	}
+}
+
+func cast[T any](input interface{}) (T, error) {
+	var zero T
+
+	if input == nil {
+		return zero, nil
+	}
+
+	out, ok := input.(T)
+	if !ok {
+		return zero, fmt.Errorf("typed pipeline: unexpected type %T, expected %v", input, reflect.TypeOf((*T)(nil)).Elem())
+	}
+
+	return out, nil
+}
+
+func castAll[T any](input []interface{}) ([]T, error) {
+	out := make([]T, len(input))
+
+	for idx, v := range input {
+		item, err := cast[T](v)
+		if err != nil {
+			return nil, err
+		}
+
+		out[idx] = item
+	}
+
+	return out, nil
+}
+
+func funcName(fn interface{}) string {
+	if reflect.ValueOf(fn).IsNil() {
+		return ""
+	}
+
+	return runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+}
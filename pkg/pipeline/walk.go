@@ -0,0 +1,60 @@
+package pipeline
+
+import (
+	"sort"
+)
+
+func walk(flow Flow, visit func(Pipe)) {
+	for _, pipe := range flow {
+		visit(pipe)
+
+		for _, sub := range subFlows(pipe) {
+			walk(sub, visit)
+		}
+	}
+}
+
+func subFlows(pipe Pipe) []Flow {
+	switch p := pipe.(type) {
+	case *Broadcast:
+		return p.Streams
+	case *Race:
+		return p.Streams
+	case *Quorum:
+		return p.Streams
+	case *Iterator:
+		return []Flow{p.Stream}
+	case *Loop:
+		return []Flow{p.Stream}
+	case *IfPipe:
+		return []Flow{p.TrueFlow, p.FalseFlow}
+	case *Switch:
+		return append(sortedFlows(p.Cases), p.Default)
+	case *PartitionPipe:
+		return sortedFlows(p.Paths)
+	case *SubPipeline:
+		return []Flow{p.pipeline().Flow}
+	default:
+		return nil
+	}
+}
+
+func sortedKeys(flows map[string]Flow) []string {
+	keys := make([]string, 0, len(flows))
+	for k := range flows {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func sortedFlows(flows map[string]Flow) []Flow {
+	out := make([]Flow, 0, len(flows))
+	for _, k := range sortedKeys(flows) {
+		out = append(out, flows[k])
+	}
+
+	return out
+}